@@ -0,0 +1,496 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"tile-puzzle-ai/puzzle"
+	"time"
+)
+
+/**
+ * Command line interface. Without a command the config is run, or created when it doesn't exist
+ *  - bench: runs every input of a config
+ *  - solve: solves a single board given on the command line
+ *  - generate: prints random puzzles in the suite file format
+ *  - verify: checks a board is valid and solvable, and optionally that a list of moves solves it
+ *  - validate: checks a config without running it
+ *  - init-config: writes the default config
+ *  - pdb: builds a pattern database, see pdb_command.go
+ **/
+
+const usage = `Usage: tile-puzzle-ai [command] [flags]
+
+Commands:
+  bench        run every input of the config (the default when no command is given)
+  solve        solve a board given on the command line, like: solve 1 2 3 / 4 0 5 / 6 7 8
+  generate     print random puzzles, one per line in the suite file format
+  verify       check a board is valid and solvable, and that -moves solves it
+  validate     check a config without running it
+  init-config  write the default config
+  pdb          build a pattern database
+  help         show this message
+
+Run "tile-puzzle-ai <command> -h" for the flags of a command
+`
+
+func runCommand(args []string) {
+	if len(args) == 0 {
+		if !ConfigExists(config_file) { // if there is no config file, create one and return
+			fmt.Printf("Couldn't find config file (%v)\n", config_file)
+			fmt.Printf("Creating %v\n", config_file)
+			createConfig(config_file)
+			return
+		}
+		config = readConfig(config_file)
+		runConfig("")
+		return
+	}
+
+	if strings.HasPrefix(args[0], "-") && args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+		benchCommand(args) // flags without a command are for bench
+		return
+	}
+
+	switch args[0] {
+	case "bench":
+		benchCommand(args[1:])
+	case "solve":
+		solveCommand(args[1:])
+	case "generate":
+		generateCommand(args[1:])
+	case "verify":
+		verifyCommand(args[1:])
+	case "validate":
+		validateCommand(args[1:])
+	case "init-config":
+		initConfigCommand(args[1:])
+	case "pdb":
+		generatePDBCommand(args[1:])
+	case "help", "-h", "-help", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Printf("Unknown command %q\n\n%v", args[0], usage)
+		os.Exit(2)
+	}
+}
+
+/**
+ * Flags shared by the commands that run a config, they override the matching config values
+ * for the default inputs and every input
+ **/
+type runFlags struct {
+	config_path string
+	log_path    string
+	seed        int64
+	heuristics  string
+	time_limit  float64
+	node_limit  int
+	stored      int
+	memory      float64
+	algorithm   string
+	pdb         string
+	workers     int
+	progress    float64
+}
+
+func addRunFlags(fs *flag.FlagSet, log_default string, log_usage string) *runFlags {
+	var f runFlags
+	fs.StringVar(&f.config_path, "config", config_file, "path of the config file")
+	fs.StringVar(&f.log_path, "log", log_default, log_usage)
+	fs.Int64Var(&f.seed, "seed", 0, "random seed, 0 picks one from the time")
+	fs.StringVar(&f.heuristics, "heuristics", "", fmt.Sprintf("comma separated heuristic numbers from 1 to %v, like 2,5", puzzle.NumHeuristics))
+	fs.Float64Var(&f.time_limit, "time", 0, "time limit in seconds for each search, like 0.5, 0 for none")
+	fs.IntVar(&f.node_limit, "nodes", 0, "most nodes each search can expand")
+	fs.IntVar(&f.stored, "stored", 0, "most nodes each search can keep in memory")
+	fs.Float64Var(&f.memory, "memory", 0, "approximate megabytes of nodes each search can keep in memory")
+	fs.StringVar(&f.algorithm, "algorithm", "", "search algorithm, \"a*\" or \"ida*\"")
+	fs.StringVar(&f.pdb, "pdb", "", "pattern database file used by heuristic 6, without "+puzzle.PDBExt)
+	fs.IntVar(&f.workers, "workers", 0, "searches to run at once (default the workers from the config)")
+	fs.Float64Var(&f.progress, "progress", 0, "seconds between progress reports on the status line, 0 turns them off")
+	return &f
+}
+
+/**
+ * Overrides the config with the flags that were set on the command line
+ **/
+func (f *runFlags) apply(fs *flag.FlagSet, c *Config) error {
+	var err error
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "seed":
+			c.Random_seed = f.seed
+			for i := range c.Inputs {
+				c.Inputs[i].Seed = 0
+			}
+		case "heuristics":
+			var heuristics []int
+			if heuristics, err = parseHeuristics(f.heuristics); err != nil {
+				return
+			}
+			c.Default_inputs.Heuristics = heuristics
+			for i := range c.Inputs {
+				c.Inputs[i].Heuristics = nil
+			}
+		case "time":
+			if f.time_limit < 0 {
+				err = fmt.Errorf("-time must not be negative")
+				return
+			}
+			c.Default_inputs.Time_limit = f.time_limit
+			for i := range c.Inputs {
+				c.Inputs[i].Time_limit = 0
+			}
+		case "nodes":
+			if f.node_limit <= 0 {
+				err = fmt.Errorf("-nodes must be positive")
+				return
+			}
+			c.Default_inputs.Node_limit = f.node_limit
+			for i := range c.Inputs {
+				c.Inputs[i].Node_limit = 0
+			}
+		case "stored":
+			if f.stored <= 0 {
+				err = fmt.Errorf("-stored must be positive")
+				return
+			}
+			c.Default_inputs.Stored_limit = f.stored
+			for i := range c.Inputs {
+				c.Inputs[i].Stored_limit = 0
+			}
+		case "memory":
+			if f.memory <= 0 {
+				err = fmt.Errorf("-memory must be positive")
+				return
+			}
+			c.Default_inputs.Memory_limit = f.memory
+			for i := range c.Inputs {
+				c.Inputs[i].Memory_limit = 0
+			}
+		case "algorithm":
+			if !validAlgorithm(puzzle.Algorithm(f.algorithm)) {
+				err = fmt.Errorf("unknown algorithm %q, use \"a*\" or \"ida*\"", f.algorithm)
+				return
+			}
+			c.Default_inputs.Algorithm = puzzle.Algorithm(f.algorithm)
+			for i := range c.Inputs {
+				c.Inputs[i].Algorithm = ""
+			}
+		case "workers":
+			c.Workers = f.workers
+		case "progress":
+			if f.progress < 0 {
+				err = fmt.Errorf("-progress must not be negative")
+				return
+			}
+			c.Progress.Interval = f.progress
+			c.Progress.Status_line = f.progress > 0
+		case "pdb":
+			c.Default_inputs.Pdb = f.pdb
+			for i := range c.Inputs {
+				c.Inputs[i].Pdb = ""
+			}
+		}
+	})
+	return err
+}
+
+func parseHeuristics(str string) ([]int, error) {
+	var heuristics []int
+	for _, field := range strings.Split(str, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 || n > puzzle.NumHeuristics {
+			return nil, fmt.Errorf("invalid heuristic %q, heuristics are 1 to %v", field, puzzle.NumHeuristics)
+		}
+		heuristics = append(heuristics, n)
+	}
+	return heuristics, nil
+}
+
+func benchCommand(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	flags := addRunFlags(fs, "", "log file path, - for stdout (default the log file from the config)")
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Printf("bench takes no arguments, got %q\n", fs.Args())
+		os.Exit(2)
+	}
+
+	// the flags are applied before the config is checked, so they can replace values it rejects
+	var errs []ConfigError
+	var decoded bool
+	if config, errs, decoded = decodeConfigFile(flags.config_path); !decoded {
+		printConfigErrors(flags.config_path, errs)
+		os.Exit(1)
+	}
+	if err := flags.apply(fs, &config); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	config = checkConfig(config, errs, flags.config_path)
+	runConfig(flags.log_path)
+}
+
+/**
+ * Solves one board, using the metrics and default inputs of the config when it exists
+ **/
+func solveCommand(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	flags := addRunFlags(fs, "-", "log file path, - for stdout")
+	goalArg := fs.String("goal", "", "goal layout, "+strings.Join(puzzle.GoalPresets, ", ")+" or the goal tiles with rows separated by / (default the goal from the config)")
+	path := fs.Bool("path", true, "log the solution path")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tile-puzzle-ai solve [flags] <board>\nThe board is rows separated by /, like 1 2 3 / 4 0 5 / 6 7 8, or the tiles of a square board in row major order\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	var initial InitialState
+	var err error
+	if initial.tiles, initial.rows, initial.cols, err = parseBoard(fs.Args()); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if err = puzzle.CheckPuzzleArr(initial.rows, initial.cols, initial.tiles); err != nil {
+		fmt.Printf("Invalid board: %v\n", err)
+		os.Exit(2)
+	}
+
+	var set_config bool = false
+	fs.Visit(func(fl *flag.Flag) { set_config = set_config || fl.Name == "config" })
+	var decode_errs []ConfigError
+	if set_config || ConfigExists(flags.config_path) { // the inputs are replaced, so only their types are checked
+		var decoded bool
+		if config, decode_errs, decoded = decodeConfigFile(flags.config_path); !decoded {
+			printConfigErrors(flags.config_path, decode_errs)
+			os.Exit(1)
+		}
+	} else {
+		config = defaultConfig()
+	}
+
+	var input = Input{Initial: initial}
+	if *goalArg != "" {
+		if input.Goal, err = puzzle.ParseGoalLayout(*goalArg); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+	}
+	config.Inputs = []Input{input}
+	config.Metrics.Solution_path = *path
+	if err := flags.apply(fs, &config); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	config = checkConfig(config, decode_errs, flags.config_path)
+	runConfig(flags.log_path)
+}
+
+/**
+ * Prints random puzzles in the suite file format so the output can be saved as a .suite file,
+ * square boards as their tiles in row major order and other boards with rows separated by /.
+ * Puzzle i uses seed + i like the random suite, and puzzles from -depth end with their optimal length
+ **/
+func generateCommand(args []string) {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	size := fs.Int("size", 3, "side length of the puzzle")
+	rows := fs.Int("rows", 0, "rows of the puzzle, overrides size")
+	cols := fs.Int("cols", 0, "columns of the puzzle, overrides size")
+	goalArg := fs.String("goal", puzzle.BlankTopLeft, "goal layout, "+strings.Join(puzzle.GoalPresets, ", ")+" or the goal tiles with rows separated by /")
+	count := fs.Int("count", 1, "number of puzzles")
+	seed := fs.Int64("seed", 0, "random seed, 0 picks one from the time")
+	swaps := fs.Int("swaps", 0, "make random moves from the goal instead of picking uniformly")
+	depth := fs.Int("depth", 0, "pick puzzles with this optimal solution length instead of uniformly")
+	fs.Parse(args)
+
+	layout, err := puzzle.ParseGoalLayout(*goalArg)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if *rows == 0 && *cols == 0 && layout.IsSet() { // shape from the goal tiles
+		*rows, *cols = layout.Shape()
+	}
+	if *rows == 0 {
+		*rows = *size
+	}
+	if *cols == 0 {
+		*cols = *size
+	}
+	if *rows < 2 || *cols < 2 || *rows**cols > 256 {
+		fmt.Println("puzzle must have at least 2 rows and columns and at most 256 tiles")
+		os.Exit(2)
+	}
+	if *swaps != 0 && *depth != 0 {
+		fmt.Println("cannot use -swaps with -depth")
+		os.Exit(2)
+	}
+	goal, err := layout.Goal(*rows, *cols)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixMilli()
+	}
+
+	var method string = "uniformly random"
+	if *swaps != 0 {
+		method = fmt.Sprintf("%v random moves", *swaps)
+	} else if *depth != 0 {
+		method = fmt.Sprintf("optimal length %v", *depth)
+	}
+	fmt.Printf("# %v %vx%v puzzles, %v, goal %v, seed %v\n", *count, *rows, *cols, method, goal.LayoutStr(), *seed)
+
+	var generator *puzzle.DepthGenerator = puzzle.NewDepthGenerator()
+	for i := 0; i < *count; i++ {
+		var p puzzle.Puzzle
+		var r *rand.Rand = rand.New(rand.NewSource(*seed + int64(i)))
+		if *swaps != 0 {
+			p = puzzle.NewPuzzleSwapped(goal, *swaps, r)
+		} else if *depth != 0 {
+			if p, err = generator.Puzzle(context.Background(), goal, *depth, r); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		} else {
+			p = puzzle.NewPuzzleUniform(goal, r)
+		}
+
+		var line string = boardStr(p)
+		if *depth != 0 {
+			line += fmt.Sprintf(" %v", *depth)
+		}
+		fmt.Println(line)
+	}
+}
+
+/**
+ * Checks a board and optionally a list of moves, exiting with 1 if the board is unsolvable or the moves don't solve it
+ **/
+func verifyCommand(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	goalArg := fs.String("goal", puzzle.BlankTopLeft, "goal layout, "+strings.Join(puzzle.GoalPresets, ", ")+" or the goal tiles with rows separated by /")
+	movesArg := fs.String("moves", "", "moves to check, letters U, D, L and R for the direction each tile slides")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: tile-puzzle-ai verify [flags] <board>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	tiles, rows, cols, err := parseBoard(fs.Args())
+	if err == nil {
+		err = puzzle.CheckPuzzleArr(rows, cols, tiles)
+	}
+	if err != nil {
+		fmt.Printf("Valid: false (%v)\n", err)
+		os.Exit(1)
+	}
+
+	layout, err := puzzle.ParseGoalLayout(*goalArg)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	goal, err := layout.Goal(rows, cols)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+
+	var p puzzle.Puzzle = puzzle.MustNewPuzzle(goal, tiles)
+	fmt.Printf("Valid: true\n")
+	fmt.Printf("Solvable: %v\n", p.IsSolvable())
+	if !p.IsSolvable() {
+		os.Exit(1)
+	}
+
+	if *movesArg == "" {
+		return
+	}
+	moves, err := puzzle.ParseMoves(*movesArg)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	end, err := p.ApplyMoves(moves)
+	if err != nil {
+		fmt.Printf("Moves: invalid, %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Moves: %v, Solved: %v\n", len(moves), end.IsSolved())
+	if !end.IsSolved() {
+		fmt.Printf("Final:\n%v", end.ToStr())
+		os.Exit(1)
+	}
+}
+
+/**
+ * Reports every problem in a config, exiting with 1 if there are any
+ **/
+func validateCommand(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	path := fs.String("config", config_file, "path of the config file")
+	fs.Parse(args)
+
+	config, errs := loadConfig(*path)
+	if len(errs) != 0 {
+		printConfigErrors(*path, errs)
+		os.Exit(1)
+	}
+	fmt.Printf("%v is valid, %v input(s)\n", *path, len(config.Inputs))
+}
+
+func initConfigCommand(args []string) {
+	fs := flag.NewFlagSet("init-config", flag.ExitOnError)
+	path := fs.String("config", config_file, "path to write the config to")
+	force := fs.Bool("force", false, "overwrite an existing config")
+	fs.Parse(args)
+
+	if ConfigExists(*path) && !*force {
+		fmt.Printf("%v already exists, use -force to overwrite it\n", *path)
+		os.Exit(1)
+	}
+	createConfig(*path)
+}
+
+/**
+ * Parses a board from the command line arguments, joined with spaces so it can be quoted or not.
+ * Tiles without / between rows are taken to be a square board
+ **/
+func parseBoard(args []string) (tiles []int, rows int, cols int, err error) {
+	if len(args) == 0 {
+		return nil, 0, 0, fmt.Errorf("no board given, write it like 1 2 3 / 4 0 5 / 6 7 8")
+	}
+
+	if tiles, rows, cols, err = puzzle.ParsePuzzleStr(strings.Join(args, " ")); err != nil {
+		return nil, 0, 0, err
+	}
+	if rows == 1 {
+		square, side := puzzle.IsSquare(len(tiles))
+		if !square {
+			return nil, 0, 0, fmt.Errorf("board has %v tiles which isn't square, separate its rows with /", len(tiles))
+		}
+		rows, cols = side, side
+	}
+	return tiles, rows, cols, nil
+}
+
+/**
+ * A board on one line, tiles in row major order for square boards and rows separated by / otherwise
+ **/
+func boardStr(p puzzle.Puzzle) string {
+	var fields []string
+	for n := 0; n < p.Size(); n++ {
+		if n > 0 && n%p.Cols() == 0 && p.Rows() != p.Cols() {
+			fields = append(fields, "/")
+		}
+		fields = append(fields, strconv.Itoa(p.GetN(n)))
+	}
+	return strings.Join(fields, " ")
+}
@@ -2,15 +2,28 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"os"
+	"strings"
+	"tile-puzzle-ai/puzzle"
 )
 
 type Config struct {
 	Log_file    string `json:"log file"`
 	Random_seed int64  `json:"random seed"`
-	Metrics     struct {
+	Workers     int    `json:"workers"` // searches run at once, 0 or 1 runs them one at a time
+	Results     struct {
+		Format      string `json:"format"` // "jsonl", "csv" or "none"
+		File        string `json:"file"`
+		No_text_log bool   `json:"no text log"`
+	} `json:"results"`
+	Progress struct {
+		Interval    float64 `json:"interval"`    // seconds between progress reports, 0 for none
+		Status_line bool    `json:"status line"` // live status line on stderr
+		Log         bool    `json:"log"`         // write the reports to a structured progress file next to the results
+	} `json:"progress"`
+	Metrics struct {
 		Initial_state       bool `json:"initial state"`
 		Num_misplaced_tiles bool `json:"num misplaced tiles"`
 		Max_solution_length bool `json:"max solution length"`
@@ -20,135 +33,276 @@ type Config struct {
 		Nodes_explored      bool `json:"nodes explored"`
 		Frontier_size       bool `json:"frontier size"`
 		Nodes_evaluated     bool `json:"nodes evaluated"`
+		Iterations          bool `json:"iterations"`
 		Solution_path       bool `json:"solution path"`
 	} `json:"metrics"`
 	Default_inputs struct {
-		Heuristics []int `json:"heuristics"`
-		Time_limit int   `json:"time limit"`
+		Heuristics   []int             `json:"heuristics"`
+		Time_limit   float64           `json:"time limit"`   // seconds
+		Node_limit   int               `json:"node limit"`   // nodes expanded
+		Stored_limit int               `json:"stored limit"` // nodes kept in memory
+		Memory_limit float64           `json:"memory limit"` // approximate megabytes of the nodes kept in memory
+		Algorithm    puzzle.Algorithm  `json:"algorithm"`
+		Pdb          string            `json:"pdb"`
+		Goal         puzzle.GoalLayout `json:"goal"`
 	} `json:"default inputs"`
-	Inputs []struct {
-		Size          int   `json:"size"`
-		Swaps         int   `json:"swaps"`
-		Misplaced     int   `json:"misplaced"`
-		Heuristics    []int `json:"heuristics"`
-		Time_limit    int   `json:"time limit"`
-		Use_prev_move bool  `json:"use prev move"`
-	} `json:"inputs"`
-}
-
-func ConfigExists() bool {
-	if _, err := os.Stat(config_file); err == nil {
+	Inputs []Input `json:"inputs"`
+}
+
+type Input struct {
+	Size          int               `json:"size"`
+	Rows          int               `json:"rows"`
+	Cols          int               `json:"cols"`
+	Initial       InitialState      `json:"initial"`
+	Goal          puzzle.GoalLayout `json:"goal"`
+	Suite         string            `json:"suite"`
+	First         int               `json:"first"`
+	Last          int               `json:"last"`
+	Swaps         int               `json:"swaps"`
+	Uniform       bool              `json:"uniform"`
+	Depth         int               `json:"depth"`
+	Seed          int64             `json:"seed"`
+	Trials        int               `json:"trials"`
+	Misplaced     int               `json:"misplaced"`
+	Heuristics    []int             `json:"heuristics"`
+	Time_limit    float64           `json:"time limit"`
+	Node_limit    int               `json:"node limit"`
+	Stored_limit  int               `json:"stored limit"`
+	Memory_limit  float64           `json:"memory limit"`
+	Algorithm     puzzle.Algorithm  `json:"algorithm"`
+	Pdb           string            `json:"pdb"`
+	Use_prev_move bool              `json:"use prev move"`
+}
+
+/**
+ * Number of rows and columns of the puzzles of an input. rows and cols override size,
+ * and without any of them an initial state or goal tiles give the shape
+ **/
+func (input Input) shape() (rows int, cols int) {
+	rows, cols = input.Size, input.Size
+	if input.Rows != 0 {
+		rows = input.Rows
+	}
+	if input.Cols != 0 {
+		cols = input.Cols
+	}
+
+	if rows == 0 && cols == 0 {
+		if input.Initial.tiles != nil {
+			return input.Initial.shape()
+		}
+		return input.Goal.Shape()
+	}
+	return rows, cols
+}
+
+func ConfigExists(path string) bool {
+	if _, err := os.Stat(path); err == nil {
 		return true
 	} else {
 		return false
 	}
 }
 
-func readConfig() Config {
-	// Open our jsonFile
-	jsonFile, err := os.Open(config_file)
-	if err != nil {
-		panic(err)
+/**
+ * Reads and checks a config, exiting with every problem found if it's invalid
+ **/
+func readConfig(path string) Config {
+	config, errs := loadConfig(path)
+	if len(errs) != 0 {
+		printConfigErrors(path, errs)
+		os.Exit(1)
 	}
+	return config
+}
 
-	defer jsonFile.Close()
+/**
+ * Reads a config and returns it with every problem found, so they can all be fixed at once.
+ * The values that decode are checked even if others have the wrong type
+ **/
+func loadConfig(path string) (Config, []ConfigError) {
+	config, errs, decoded := decodeConfigFile(path)
+	if !decoded {
+		return config, errs
+	}
+	return config, checkDecoded(&config, errs)
+}
 
-	// read our opened jsonFile as a byte array.
-	byteValue, err2 := io.ReadAll(jsonFile)
-	if err2 != nil {
-		fmt.Println(err)
-		os.Exit(1)
+/**
+ * Reads a config without checking its values, only that they have the right types.
+ * decoded is false if the file couldn't be read or isn't valid JSON
+ **/
+func decodeConfigFile(path string) (config Config, errs []ConfigError, decoded bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, []ConfigError{{Msg: fmt.Sprintf("couldn't read config file (%v), run init-config to create one", err)}}, false
 	}
+	return decodeConfig(data)
+}
 
-	var config Config
-	if err3 := json.Unmarshal(byteValue, &config); err3 != nil {
-		fmt.Printf("Invalid config file. Delete %v and run program to generate a new one\n", config_file)
-		os.Exit(1)
+func printConfigErrors(path string, errs []ConfigError) {
+	fmt.Printf("Invalid config %v, %v problem(s):\n", path, len(errs))
+	for _, err := range errs {
+		fmt.Printf("  %v\n", err)
 	}
+}
 
-	for _, input := range config.Inputs {
-		if (input.Misplaced != 0) && (input.Swaps != 0) {
-			panic("cannot specify both swaps and misplaced in config.inputs")
-		}
+/**
+ * The config createConfig writes, used as the base for commands run without a config file
+ **/
+func defaultConfig() Config {
+	var config Config
+	if err := json.Unmarshal([]byte(strings.Join(default_config, "\n")), &config); err != nil {
+		panic(err)
 	}
+	return config
+}
 
+/**
+ * Checks a config changed by the command line along with the problems found decoding it,
+ * exiting with every problem found if it's invalid
+ **/
+func checkConfig(config Config, decode_errs []ConfigError, path string) Config {
+	if errs := checkDecoded(&config, decode_errs); len(errs) != 0 {
+		printConfigErrors(path, errs)
+		os.Exit(1)
+	}
 	return config
 }
 
-func createConfig() {
-	var contents = []string{
-		"{",
-		"\t\"log file\": \"log\",",
-		"\t\"random seed\": 0,",
-		"\t\"metrics\": {",
-		"\t\t\"initial state\": true,",
-		"\t\t\"num misplaced tiles\": true,",
-		"\t\t\"max solution length\": true,",
-		"\t\t\"status\": true,",
-		"\t\t\"execution time\": true,",
-		"\t\t\"solution length\": true,",
-		"\t\t\"nodes explored\": true,",
-		"\t\t\"frontier size\": true,",
-		"\t\t\"nodes evaluated\": true,",
-		"\t\t\"solution path\": false",
-		"\t},",
-		"\t\"default inputs\": {",
-		"\t\t\"heuristics\": [2],",
-		"\t\t\"time limit\": 60",
-		"\t},",
-		"\t\"inputs\": [",
-		"\t\t{",
-		"\t\t\t\"size\": 2,",
-		"\t\t\t\"misplaced\": 3",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 9,",
-		"\t\t\t\"swaps\": 40",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 3,",
-		"\t\t\t\"misplaced\": 9,",
-		"\t\t\t\"heuristics\": [1, 2, 3, 4]",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 3,",
-		"\t\t\t\"swaps\": 20",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 4,",
-		"\t\t\t\"swaps\": 20,",
-		"\t\t\t\"heuristics\": [1, 2, 3, 4]",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 5,",
-		"\t\t\t\"misplaced\": 10,",
-		"\t\t\t\"time limit\": 100",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 4,",
-		"\t\t\t\"misplaced\": 16,",
-		"\t\t\t\"time limit\": 0",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 4,",
-		"\t\t\t\"misplaced\": 25,",
-		"\t\t\t\"time limit\": 0",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 4,",
-		"\t\t\t\"misplaced\": 25,",
-		"\t\t\t\"time limit\": 0,",
-		"\t\t\t\"use prev move\": true",
-		"\t\t},",
-		"\t\t{",
-		"\t\t\t\"size\": 5,",
-		"\t\t\t\"misplaced\": 15,",
-		"\t\t\t\"time limit\": 60",
-		"\t\t}",
-		"\t]",
-		"}",
-	}
-	f, err := os.Create(config_file)
+var default_config = []string{
+	"{",
+	"\t\"log file\": \"log\",",
+	"\t\"random seed\": 0,",
+	"\t\"workers\": 1,",
+	"\t\"results\": {",
+	"\t\t\"format\": \"none\",",
+	"\t\t\"file\": \"results\",",
+	"\t\t\"no text log\": false",
+	"\t},",
+	"\t\"progress\": {",
+	"\t\t\"interval\": 1,",
+	"\t\t\"status line\": true,",
+	"\t\t\"log\": false",
+	"\t},",
+	"\t\"metrics\": {",
+	"\t\t\"initial state\": true,",
+	"\t\t\"num misplaced tiles\": true,",
+	"\t\t\"max solution length\": true,",
+	"\t\t\"status\": true,",
+	"\t\t\"execution time\": true,",
+	"\t\t\"solution length\": true,",
+	"\t\t\"nodes explored\": true,",
+	"\t\t\"frontier size\": true,",
+	"\t\t\"nodes evaluated\": true,",
+	"\t\t\"iterations\": true,",
+	"\t\t\"solution path\": false",
+	"\t},",
+	"\t\"default inputs\": {",
+	"\t\t\"heuristics\": [2],",
+	"\t\t\"time limit\": 60,",
+	"\t\t\"algorithm\": \"a*\"",
+	"\t},",
+	"\t\"inputs\": [",
+	"\t\t{",
+	"\t\t\t\"size\": 2,",
+	"\t\t\t\"misplaced\": 3",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 9,",
+	"\t\t\t\"swaps\": 40",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"initial\": \"7 2 4 / 5 0 6 / 8 3 1\",",
+	"\t\t\t\"heuristics\": [2, 5]",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"rows\": 3,",
+	"\t\t\t\"cols\": 4,",
+	"\t\t\t\"swaps\": 60",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"swaps\": 40,",
+	"\t\t\t\"goal\": \"blank-bottom-right\"",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"initial\": \"2 8 3 / 1 6 4 / 7 0 5\",",
+	"\t\t\t\"goal\": \"spiral\"",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 3,",
+	"\t\t\t\"uniform\": true,",
+	"\t\t\t\"seed\": 7",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 3,",
+	"\t\t\t\"uniform\": true,",
+	"\t\t\t\"trials\": 20,",
+	"\t\t\t\"heuristics\": [2, 5, 7]",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"depth\": 30,",
+	"\t\t\t\"heuristics\": [5, 8]",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"suite\": \"8-puzzle\",",
+	"\t\t\t\"first\": 271,",
+	"\t\t\t\"last\": 280",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 3,",
+	"\t\t\t\"misplaced\": 9,",
+	"\t\t\t\"heuristics\": [1, 2, 3, 4]",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 3,",
+	"\t\t\t\"swaps\": 20",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"swaps\": 20,",
+	"\t\t\t\"heuristics\": [1, 2, 3, 4]",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 5,",
+	"\t\t\t\"misplaced\": 10,",
+	"\t\t\t\"time limit\": 100",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"misplaced\": 16,",
+	"\t\t\t\"time limit\": 0",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"misplaced\": 25,",
+	"\t\t\t\"time limit\": 0",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"misplaced\": 25,",
+	"\t\t\t\"time limit\": 0,",
+	"\t\t\t\"use prev move\": true",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 5,",
+	"\t\t\t\"misplaced\": 15,",
+	"\t\t\t\"time limit\": 60",
+	"\t\t},",
+	"\t\t{",
+	"\t\t\t\"size\": 4,",
+	"\t\t\t\"misplaced\": 25,",
+	"\t\t\t\"time limit\": 60,",
+	"\t\t\t\"algorithm\": \"ida*\"",
+	"\t\t}",
+	"\t]",
+	"}",
+}
+
+func createConfig(path string) {
+	f, err := os.Create(path)
 
 	if err != nil {
 		panic(err)
@@ -156,14 +310,78 @@ func createConfig() {
 
 	defer f.Close()
 
-	for _, line := range contents {
+	for _, line := range default_config {
 		_, err := f.WriteString(line + "\n")
 		if err != nil {
 			panic(err)
 		}
 	}
 
-	fmt.Printf("Config created at %v\n", config_file)
+	fmt.Printf("Config created at %v\n", path)
+}
+
+/**
+ * Initial state of an input given as a flat array of tiles in row major order,
+ * or as a string of rows separated by / like "1 2 3 / 4 0 5 / 6 7 8"
+ **/
+type InitialState struct {
+	tiles []int
+	rows  int // rows and cols are only known when given as a string
+	cols  int
+}
+
+func (s *InitialState) UnmarshalJSON(data []byte) error {
+	var arr []int
+	if err := json.Unmarshal(data, &arr); err == nil {
+		s.tiles = arr
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return errors.New("initial must be an array of tiles or a string like \"1 2 3 / 4 0 5 / 6 7 8\"")
+	}
+
+	arr, rows, cols, err := puzzle.ParsePuzzleStr(str)
+	if err != nil {
+		return fmt.Errorf("initial %q: %v", str, err)
+	}
+	s.tiles, s.rows, s.cols = arr, rows, cols
+	return nil
+}
+
+/**
+ * Shape of an initial state, from its rows when given as a string or taken to be square for an array
+ **/
+func (s InitialState) shape() (rows int, cols int) {
+	if s.rows != 0 {
+		return s.rows, s.cols
+	}
+	_, len := puzzle.IsSquare(len(s.tiles))
+	return len, len
+}
+
+/**
+ * Checks an initial state is a valid puzzle and doesn't conflict with the other fields of its input
+ **/
+func checkInitial(input Input) error {
+	if input.Swaps != 0 || input.Misplaced != 0 {
+		return errors.New("cannot specify initial with swaps or misplaced")
+	}
+
+	rows, cols := input.shape()
+	if input.Initial.rows != 0 && (rows != input.Initial.rows || cols != input.Initial.cols) {
+		return fmt.Errorf("input is %vx%v but initial is a %vx%v puzzle", rows, cols, input.Initial.rows, input.Initial.cols)
+	}
+
+	return puzzle.CheckPuzzleArr(rows, cols, input.Initial.tiles)
+}
+
+/**
+ * an empty algorithm is valid and falls back to the default
+ **/
+func validAlgorithm(a puzzle.Algorithm) bool {
+	return a == "" || a == puzzle.AStar || a == puzzle.IDAStar
 }
 
 func openLogFile(filename string) *os.File {
@@ -1,48 +1,180 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"math/rand"
 	"os"
+	"os/signal"
+	"tile-puzzle-ai/puzzle"
+	"tile-puzzle-ai/search"
 	"time"
 )
 
-const config_file = "config.json"
+const config_file = "config.json" // default config path
 
 var config Config
 var logger log.Logger
 var logfile *os.File
+var results *resultsFile                  // nil unless the config asks for structured results
+var progress_file *resultsFile            // nil unless the config asks for progress to be logged
+var summary_file *resultsFile             // nil unless there are structured results and an input is summarised
+var pdbs = map[string]*puzzle.PatternDB{} // pattern databases loaded so far by name
 
-func init() {
-
-	if !ConfigExists() { // if there is no config file, create one and return
-		fmt.Printf("Couldn't find config file (%v)\n", config_file)
-		fmt.Printf("Creating %v\n", config_file)
-		createConfig()
-		os.Exit(0)
-	}
-
-	config = readConfig()
+func main() {
+	runCommand(os.Args[1:])
+}
 
+/**
+ * Runs every input of the config, logging to log_path. An empty log_path uses the log file
+ * from the config and "-" logs to stdout
+ **/
+func runConfig(log_path string) {
 	if config.Random_seed == 0 { // set global random seed
 		config.Random_seed = int64(time.Now().UnixMilli())
 	}
-}
 
-func main() {
 	// open logfile and print header
-	if config.Log_file == "" {
+	switch {
+	case config.Results.No_text_log:
+	case log_path == "-":
+		logfile = os.Stdout
+	case log_path != "":
+		logfile = openLogFile(log_path)
+	case config.Log_file == "":
 		logfile = openLogFile(time.Now().Format("15-04-05") + ".txt")
-	} else {
+	default:
 		logfile = openLogFile(config.Log_file + ".txt")
 	}
 
-	defer logfile.Close()
-	logger.SetOutput(logfile)
+	if logfile != nil && logfile != os.Stdout {
+		defer logfile.Close()
+	}
+	if config.Results.No_text_log {
+		logger.SetOutput(io.Discard)
+	} else {
+		logger.SetOutput(logfile)
+	}
+
+	var results_name string = resultsFileName() // once, as without a name it comes from the time
+	if writesResults(config) {
+		results = openResults(results_name+"."+config.Results.Format, config.Results.Format, csv_header)
+		defer results.close()
+	}
+	if config.Progress.Interval > 0 && config.Progress.Log {
+		progress_file = openResults(results_name+"-progress."+config.Results.Format, config.Results.Format, progress_csv_header)
+		defer progress_file.close()
+	}
+	if config.Progress.Interval > 0 && config.Progress.Status_line && isTerminal(os.Stderr) {
+		status = &statusLine{out: os.Stderr}
+	}
 	logger.Println(time.Now().Format("15:04:05 02/01/06"))
 	logger.Printf("Random Seed: %v", config.Random_seed)
+	if config.Workers > 1 {
+		logger.Printf("Workers: %v", config.Workers)
+	}
 	logger.Print(logFileSpacer())
 
+	var ctx context.Context = interruptContext() // before the puzzles are generated, which can take a while
+	inputs, err := buildRuns(ctx)
+	if err != nil {
+		logger.Printf("Interrupted while generating the puzzles of input %v, no runs started\n", len(inputs)+1)
+		return
+	}
+	var all []*run
+	for _, in := range inputs {
+		all = append(all, in.runs...)
+		if in.summarize && writesResults(config) && summary_file == nil {
+			summary_file = openResults(results_name+"-summary."+config.Results.Format, config.Results.Format, summary_csv_header)
+			defer summary_file.close()
+		}
+	}
+	runAll(ctx, all, config.Workers)
+
+	// write each run out in order as soon as it and every run before it has finished
+	for n, in := range inputs {
+		var trials = newTrialStats(in.heuristics)
+		var interrupted bool = false
+		for _, r := range in.runs {
+			if <-r.done; r.skipped {
+				interrupted = true
+				break
+			}
+			if logfile == os.Stdout {
+				status.clear()
+			}
+			logfileWrite(r.log.Bytes())
+			logger.Print(logFileSpacer())
+			if results != nil {
+				results.write(newResultRecord(r))
+			}
+			trials.add(r.heuristic_num, r.result)
+		}
+
+		if in.summarize && trials.runs() > 0 { // summarise trials and suites, or the part that ran
+			var summaries []SummaryRecord = trialSummaries(in.input, trials)
+			logTrialSummary(summaries)
+			for _, summary := range summaries {
+				if summary_file != nil {
+					summary_file.write(summary)
+				}
+			}
+		}
+
+		if interrupted {
+			var left int = 0
+			for _, in := range inputs[n:] {
+				for _, r := range in.runs {
+					<-r.done
+					if r.skipped {
+						left++
+					}
+				}
+			}
+			logger.Printf("Interrupted, %v run(s) not started\n", left)
+			return
+		}
+	}
+}
+
+/**
+ * Context cancelled by the first interrupt, so Ctrl-C stops the searches cleanly and the log and
+ * results so far are still written. A second interrupt quits straight away
+ **/
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	var interrupt = make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		signal.Stop(interrupt)
+		status.clear()
+		fmt.Fprintln(os.Stderr, "Interrupted, stopping the searches running and writing the log. Interrupt again to quit now")
+		cancel()
+	}()
+	return ctx
+}
+
+/**
+ * The runs of one input, with whether they are several instances to summarise
+ **/
+type inputRuns struct {
+	input      int // from 1
+	heuristics []int
+	runs       []*run
+	summarize  bool
+}
+
+/**
+ * Generates the puzzles of every input and makes a run for each heuristic, logging the
+ * description of each puzzle to its run. Returns ctx's error with the inputs generated so far
+ * if ctx is done while generating a puzzle
+ **/
+func buildRuns(ctx context.Context) ([]inputRuns, error) {
+	var inputs []inputRuns
+	var generator *puzzle.DepthGenerator = puzzle.NewDepthGenerator() // shares searches between inputs with the same goal
 	for i, input := range config.Inputs {
 		// get heuristics for this input
 		var heuristics []int
@@ -53,56 +185,316 @@ func main() {
 		}
 
 		// get time limit for this input
-		var time_limit int
+		var time_limit float64
 		if input.Time_limit <= 0 {
 			time_limit = config.Default_inputs.Time_limit
 		} else {
 			time_limit = input.Time_limit
 		}
 
-		// for each heuristic
-		for _, heuristic_num := range heuristics {
-			logger.Printf("Puzzle: %v-%v", i+1, heuristic_num)
+		// get node and memory limits for this input
+		var limits search.Limits = search.Limits{
+			Nodes:  config.Default_inputs.Node_limit,
+			Stored: config.Default_inputs.Stored_limit,
+			Bytes:  megabytes(config.Default_inputs.Memory_limit),
+		}
+		if input.Node_limit > 0 {
+			limits.Nodes = input.Node_limit
+		}
+		if input.Stored_limit > 0 {
+			limits.Stored = input.Stored_limit
+		}
+		if input.Memory_limit > 0 {
+			limits.Bytes = megabytes(input.Memory_limit)
+		}
+
+		// get pattern database for this input
+		var pdb_name string
+		if input.Pdb != "" {
+			pdb_name = input.Pdb
+		} else {
+			pdb_name = config.Default_inputs.Pdb
+		}
+
+		// get algorithm for this input
+		var algorithm puzzle.Algorithm
+		if input.Algorithm != "" {
+			algorithm = input.Algorithm
+		} else if config.Default_inputs.Algorithm != "" {
+			algorithm = config.Default_inputs.Algorithm
+		} else {
+			algorithm = puzzle.AStar
+		}
+
+		rows, cols := input.shape()
 
-			var p Puzzle // find what type of input was specified
+		// get random seed for this input, every input gets its own so they don't share random walks
+		var seed int64
+		if input.Seed != 0 {
+			seed = input.Seed
+		} else {
+			seed = deriveSeed(config.Random_seed, i)
+		}
+
+		// get the puzzles for this input, a suite can give several
+		var instances []puzzle.Instance
+		if input.Suite != "" {
+			var err error
+			if instances, err = puzzle.GetSuite(input.Suite, input.Goal, rows, cols, input.First, input.Last, seed); err != nil {
+				fmt.Printf("Invalid suite in config.inputs[%v]: %v\n", i, err)
+				os.Exit(1)
+			}
+		} else {
+			goal, err := input.Goal.Goal(rows, cols) // checked when the config was read
+			if err != nil {
+				panic(err)
+			}
+			instances = []puzzle.Instance{{Goal: goal, Optimal: -1}} // generated from the input below
+			if input.Trials > 1 {
+				instances = nil
+				for t := 1; t <= input.Trials; t++ { // trials are numbered like suite instances
+					instances = append(instances, puzzle.Instance{Number: t, Goal: goal, Optimal: -1})
+				}
+			}
+		}
+
+		var in = inputRuns{input: i + 1, heuristics: heuristics, summarize: len(instances) > 1}
+		for _, instance := range instances {
+			// trials after the first are seeded one after another, like the random suite
+			var trial_seed int64 = seed
+			if instance.Suite == "" && instance.Number > 1 {
+				trial_seed += int64(instance.Number - 1)
+			}
+
+			var p puzzle.Puzzle // find what type of input was specified
 			var swaps int
-			if input.Swaps != 0 {
-				p = newPuzzleSwapped(input.Size, input.Swaps)
+			var optimal int = instance.Optimal
+			var puzzle_seed int64 // seed the puzzle was generated from, for the results
+			if instance.Tiles != nil {
+				p = puzzle.MustNewPuzzle(instance.Goal, instance.Tiles)
+				swaps = 0
+				if instance.Suite == "random" {
+					puzzle_seed = seed + int64(instance.Number)
+				}
+			} else if input.Initial.tiles != nil {
+				p = puzzle.MustNewPuzzle(instance.Goal, input.Initial.tiles)
+				swaps = 0
+			} else if input.Uniform {
+				p = puzzle.NewPuzzleUniform(instance.Goal, rand.New(rand.NewSource(trial_seed)))
+				swaps = 0
+				puzzle_seed = trial_seed
+			} else if input.Depth != 0 {
+				var err error
+				if p, err = generator.Puzzle(ctx, instance.Goal, input.Depth, rand.New(rand.NewSource(trial_seed))); ctx.Err() != nil {
+					return inputs, ctx.Err()
+				} else if err != nil {
+					fmt.Printf("Invalid depth in config.inputs[%v]: %v\n", i, err)
+					os.Exit(1)
+				}
+				swaps = 0
+				optimal = input.Depth
+				puzzle_seed = trial_seed
+			} else if input.Swaps != 0 {
+				p = puzzle.NewPuzzleSwapped(instance.Goal, input.Swaps, rand.New(rand.NewSource(trial_seed)))
 				swaps = input.Swaps
+				puzzle_seed = trial_seed
 			} else if input.Misplaced != 0 {
-				p, swaps = newPuzzleMisplaced(input.Size, input.Misplaced)
+				p, swaps = puzzle.NewPuzzleMisplaced(instance.Goal, input.Misplaced, rand.New(rand.NewSource(trial_seed)))
+				puzzle_seed = trial_seed
 			} else {
-				p = newPuzzleSolved(input.Size)
+				p = puzzle.NewPuzzleSolved(instance.Goal)
 				swaps = 0
 			}
-			logger.Printf("Size: %v\n", p.len())
 
-			if config.Metrics.Initial_state {
-				logger.Printf("Initial: \n%v", p.toStr())
-			}
+			// for each heuristic
+			for _, heuristic_num := range heuristics {
+				var label string = fmt.Sprintf("%v-%v", i+1, heuristic_num)
+				if instance.Number != 0 {
+					label = fmt.Sprintf("%v.%v-%v", i+1, instance.Number, heuristic_num)
+				}
+				var r *run = newRun(label)
+				r.logger.Printf("Puzzle: %v", label)
+				r.logger.Printf("Size: %v\n", p.ShapeStr())
+				if p.Goal().Name() != puzzle.BlankTopLeft {
+					r.logger.Printf("Goal: %v\n", p.Goal().LayoutStr())
+				}
 
-			logger.Printf("Initial Misplaced Tiles: %v / %v\n", h1(p), p.size()-1)
-			logger.Printf("Swaps Used to Generate: %v\n", swaps)
-			if input.Use_prev_move {
-				logger.Printf("Using prev node in successor generation\n")
-			}
+				if config.Metrics.Initial_state {
+					r.logger.Printf("Initial: \n%v", p.ToStr())
+				}
 
-			switch heuristic_num {
-			case 1:
-				logger.Print("Heuristic: Number of Misplaced (1)")
-			case 2:
-				logger.Print("Heuristic: Manhattan Distance (2)")
-			case 3:
-				logger.Print("Heuristic: Maxsort Swaps (3)")
-			case 4:
-				logger.Print("Heuristic: Euclidian Distance (4)")
-			default:
-				logger.Printf("Heuristic: Unknown (%v)", heuristic_num)
-			}
-			logger.Print("\n")
+				r.logger.Printf("Initial Misplaced Tiles: %v / %v\n", puzzle.MisplacedTiles(p), p.Size()-1)
+				if instance.Suite == "random" {
+					r.logger.Printf("Instance: %v #%v, Seed: %v\n", instance.Suite, instance.Number, puzzle_seed)
+				} else if instance.Suite != "" {
+					r.logger.Printf("Instance: %v #%v\n", instance.Suite, instance.Number)
+				} else if input.Initial.tiles != nil {
+					r.logger.Printf("Initial State From Config\n")
+				} else if input.Uniform {
+					r.logger.Printf("Uniformly Random Solvable State, Seed: %v\n", trial_seed)
+				} else if input.Depth != 0 {
+					r.logger.Printf("Random State at Depth %v, Seed: %v\n", input.Depth, trial_seed)
+				} else if input.Swaps != 0 || input.Misplaced != 0 {
+					r.logger.Printf("Swaps Used to Generate: %v, Seed: %v\n", swaps, trial_seed)
+				} else {
+					r.logger.Printf("Swaps Used to Generate: %v\n", swaps)
+				}
+				if instance.Suite == "" && instance.Number != 0 {
+					r.logger.Printf("Trial: %v of %v\n", instance.Number, len(instances))
+				}
+				if optimal >= 0 {
+					r.logger.Printf("Optimal Solution Length: %v\n", optimal)
+				}
+				if input.Use_prev_move && algorithm == puzzle.AStar {
+					r.logger.Printf("Using prev node in successor generation\n")
+				}
 
-			solve(p, heuristic_num, time_limit, !input.Use_prev_move)
-			logger.Print(logFileSpacer())
+				switch algorithm {
+				case puzzle.AStar:
+					r.logger.Print("Algorithm: A*")
+				case puzzle.IDAStar:
+					r.logger.Print("Algorithm: IDA*")
+				}
+
+				var pdb *puzzle.PatternDB
+				if heuristic_num == puzzle.PDBHeuristic {
+					pdb = getPatternDB(pdb_name, puzzle.NewPuzzleSolved(p.Goal()))
+				}
+				if heuristic_num == 7 || heuristic_num == 8 { // so building the tables isn't timed as part of the search
+					puzzle.PrepareWalkingDistance(p.Goal())
+				}
+
+				switch heuristic_num {
+				case 1:
+					r.logger.Print("Heuristic: Number of Misplaced (1)")
+				case 2:
+					r.logger.Print("Heuristic: Manhattan Distance (2)")
+				case 3:
+					r.logger.Print("Heuristic: Maxsort Swaps (3)")
+				case 4:
+					r.logger.Print("Heuristic: Euclidian Distance (4)")
+				case 5:
+					r.logger.Print("Heuristic: Linear Conflict (5)")
+				case puzzle.PDBHeuristic:
+					r.logger.Printf("Heuristic: Pattern Database %v (%v)", pdb_name, puzzle.PDBHeuristic)
+				case 7:
+					r.logger.Print("Heuristic: Walking Distance (7)")
+				case 8:
+					r.logger.Print("Heuristic: Max of Walking Distance and Linear Conflict (8)")
+				default:
+					r.logger.Printf("Heuristic: Unknown (%v)", heuristic_num)
+				}
+				r.logger.Print("\n")
+
+				h, err := puzzle.GetHeuristic(heuristic_num, pdb)
+				if err != nil { // checked by validateConfig
+					fmt.Printf("Input %v: %v\n", i+1, err)
+					os.Exit(1)
+				}
+
+				r.input, r.instance, r.seed, r.initial, r.optimal = i+1, instance, puzzle_seed, p, optimal
+				r.heuristic_num, r.h = heuristic_num, h
+				r.algorithm, r.time_limit, r.limits, r.skip_reverse = algorithm, time_limit, limits, !input.Use_prev_move
+				in.runs = append(in.runs, r)
+			}
 		}
+		inputs = append(inputs, in)
+	}
+	return inputs, nil
+}
+
+/**
+ * Writes a finished run's log to the log file, unless the text log is off
+ **/
+func logfileWrite(b []byte) {
+	if config.Results.No_text_log {
+		return
+	}
+	if _, err := logfile.Write(b); err != nil {
+		panic(err)
+	}
+}
+
+/**
+ * Bytes in mb megabytes, for the memory limit
+ **/
+func megabytes(mb float64) int64 {
+	return int64(mb * (1 << 20))
+}
+
+/**
+ * Seed for the input at index, mixed from the master seed with splitmix64 so neighbouring
+ * inputs and master seeds give unrelated seeds. Never 0, as an input seed of 0 means unset
+ **/
+func deriveSeed(master int64, index int) int64 {
+	var z uint64 = uint64(master) + uint64(index+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	if z == 0 {
+		return 1
+	}
+	return int64(z >> 1) // positive, so it's easy to copy into a config
+}
+
+/**
+ * Name of the structured results file without its extension, the log file's name unless the config sets one
+ **/
+func resultsFileName() string {
+	if config.Results.File != "" {
+		return config.Results.File
+	} else if config.Log_file != "" {
+		return config.Log_file
+	}
+	return time.Now().Format("15-04-05")
+}
+
+/**
+ * Loads the pattern database with the given name the first time it is used.
+ * Exits if it can't be read or was built for a different board size or goal,
+ * which validateConfig checks before anything runs
+ **/
+func getPatternDB(name string, goal puzzle.Puzzle) *puzzle.PatternDB {
+	pdb, err := loadPatternDB(name)
+	if err != nil {
+		fmt.Printf("Couldn't load pattern database: %v\n", err)
+		fmt.Printf("Generate one with: %v\n", pdbCommand(name, goal))
+		os.Exit(1)
+	}
+
+	if err := pdb.CheckCompatible(goal); err != nil {
+		fmt.Printf("Can't use pattern database %v: %v\n", name, err)
+		os.Exit(1)
+	}
+
+	return pdb
+}
+
+/**
+ * Reads the pattern database with the given name, or returns it if it's already been read
+ **/
+func loadPatternDB(name string) (*puzzle.PatternDB, error) {
+	if pdb, ok := pdbs[name]; ok {
+		return pdb, nil
+	}
+	pdb, err := puzzle.LoadPatternDB(name + puzzle.PDBExt)
+	if err != nil {
+		return nil, err
+	}
+	pdbs[name] = pdb
+	return pdb, nil
+}
+
+/**
+ * The command that builds the pattern database name for the board and goal of goal
+ **/
+func pdbCommand(name string, goal puzzle.Puzzle) string {
+	var args string = fmt.Sprintf("-size %v", goal.Cols())
+	if goal.Rows() != goal.Cols() {
+		args = fmt.Sprintf("-rows %v -cols %v", goal.Rows(), goal.Cols())
+	}
+	if goal.Goal().Name() != puzzle.BlankTopLeft {
+		args += fmt.Sprintf(" -goal %q", goal.Goal().LayoutStr())
 	}
+	return fmt.Sprintf("go run . pdb %v -out %v", args, name)
 }
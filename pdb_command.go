@@ -0,0 +1,87 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"tile-puzzle-ai/puzzle"
+)
+
+/**
+ * Command to generate a pattern database, run with
+ *   - go run . pdb -size 4 -patterns "1,4,5,8,9,12/2,3,6,7,10,11/13,14,15" -out pdb-4
+ *   - -rows and -cols can be given instead of -size for rectangular puzzles
+ *   - -goal sets the goal layout, a preset name or tiles like "1 2 3 / 8 0 4 / 7 6 5"
+ **/
+func generatePDBCommand(args []string) {
+	fs := flag.NewFlagSet("pdb", flag.ExitOnError)
+	size := fs.Int("size", 4, "side length of the puzzle")
+	rows := fs.Int("rows", 0, "rows of the puzzle, overrides size")
+	cols := fs.Int("cols", 0, "columns of the puzzle, overrides size")
+	goalArg := fs.String("goal", puzzle.BlankTopLeft, "goal layout, "+strings.Join(puzzle.GoalPresets, ", ")+" or the goal tiles with rows separated by /")
+	patternsArg := fs.String("patterns", "", "tiles of each pattern, comma separated with patterns separated by /")
+	out := fs.String("out", "", "file name to write, "+puzzle.PDBExt+" is appended (default pdb-<size>, with -<goal> for preset goals other than "+puzzle.BlankTopLeft+")")
+	fs.Parse(args)
+
+	layout, err := puzzle.ParseGoalLayout(*goalArg)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *rows == 0 && *cols == 0 && layout.IsSet() { // shape from the goal tiles
+		*rows, *cols = layout.Shape()
+	}
+	if *rows == 0 {
+		*rows = *size
+	}
+	if *cols == 0 {
+		*cols = *size
+	}
+	if *rows < 2 || *cols < 2 || *rows**cols > 256 {
+		fmt.Println("puzzle must have at least 2 rows and columns and at most 256 tiles")
+		os.Exit(1)
+	}
+
+	goalLayout, err := layout.Goal(*rows, *cols)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var goal puzzle.Puzzle = puzzle.NewPuzzleSolved(goalLayout)
+	var n int = goal.Size()
+	var patterns [][]int
+	if *patternsArg == "" {
+		patterns = puzzle.DefaultPatterns(goal)
+	} else {
+		var err error
+		if patterns, err = puzzle.ParsePatterns(*patternsArg); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+	}
+
+	if err := puzzle.ValidatePatterns(n, patterns); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var filename string = *out
+	if filename == "" {
+		filename = "pdb-" + goal.ShapeStr()
+		if goalLayout.Name() != puzzle.BlankTopLeft && goalLayout.Name() != puzzle.CustomGoal {
+			filename += "-" + goalLayout.Name()
+		}
+	}
+	filename += puzzle.PDBExt
+
+	fmt.Printf("Building pattern database for size %v, goal %v with patterns %v\n", goal.ShapeStr(), goalLayout.LayoutStr(), patterns)
+	db := puzzle.BuildPatternDB(goal, patterns)
+	if err := db.Save(filename); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	fmt.Printf("Pattern database written to %v\n", filename)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"sync"
+	"tile-puzzle-ai/puzzle"
+	"tile-puzzle-ai/search"
+)
+
+/**
+ * Worker pool for the searches of a config. Every puzzle is generated up front in config order,
+ * then the searches run on the workers with each writing its log to its own buffer. The buffers are
+ * written out in config order as they finish, so the log reads the same whatever the scheduling
+ **/
+
+/**
+ * One search of a puzzle with a heuristic, labelled like the Puzzle line of the log
+ **/
+type run struct {
+	label         string
+	input         int // from 1
+	instance      puzzle.Instance
+	seed          int64 // seed the puzzle was generated from, 0 for given boards
+	initial       puzzle.Puzzle
+	heuristic_num int
+	h             puzzle.Heuristic
+	algorithm     puzzle.Algorithm
+	time_limit    float64 // seconds, 0 for no limit
+	limits        search.Limits
+	skip_reverse  bool
+	optimal       int
+
+	log     bytes.Buffer
+	logger  *log.Logger
+	result  puzzle.Result
+	shared  int  // most other searches running at the same time as this one
+	skipped bool // never started because ctx was done first
+	done    chan struct{}
+}
+
+func newRun(label string) *run {
+	var r = &run{label: label, done: make(chan struct{})}
+	r.logger = log.New(&r.log, "", logger.Flags())
+	return r
+}
+
+/**
+ * Solves every run on workers goroutines, closing each run's done channel when it finishes.
+ * Once ctx is done the searches running stop and the runs left are skipped
+ **/
+func runAll(ctx context.Context, runs []*run, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var queue = make(chan *run)
+	go func() {
+		for _, r := range runs {
+			queue <- r
+		}
+		close(queue)
+	}()
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			for r := range queue {
+				if ctx.Err() != nil {
+					r.skipped = true
+				} else {
+					solve(ctx, r)
+				}
+				close(r.done)
+			}
+		}()
+	}
+}
+
+var searching = map[*run]bool{} // runs searching right now
+var searching_lock sync.Mutex
+
+/**
+ * Marks a run as searching, raising the shared count of every run searching alongside it
+ **/
+func startSearch(r *run) {
+	searching_lock.Lock()
+	defer searching_lock.Unlock()
+	searching[r] = true
+	for other := range searching {
+		if len(searching)-1 > other.shared {
+			other.shared = len(searching) - 1
+		}
+	}
+}
+
+/**
+ * Marks a run as finished, returning the most other searches it ran alongside
+ **/
+func endSearch(r *run) int {
+	searching_lock.Lock()
+	defer searching_lock.Unlock()
+	delete(searching, r)
+	return r.shared
+}
@@ -0,0 +1,151 @@
+package puzzle
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"tile-puzzle-ai/search"
+)
+
+const depth_bfs_max_states = 2000000 // largest breadth first search before falling back to solving random walks
+const depth_max_attempts = 1000
+
+/**
+ * Generates puzzles of a given optimal length, keeping the breadth first search from each goal so later
+ * puzzles with the same goal don't repeat it. The searches are kept as long as the generator is,
+ * so make one for a batch of puzzles and drop it afterwards. Safe to use from several goroutines
+ **/
+type DepthGenerator struct {
+	layers map[string]depthLayers // by goal id
+	lock   sync.Mutex
+}
+
+func NewDepthGenerator() *DepthGenerator {
+	return &DepthGenerator{layers: map[string]depthLayers{}}
+}
+
+/**
+ * States at each distance from a goal, up to the deepest depth asked for so far
+ **/
+type depthLayers struct {
+	layers   [][]Puzzle
+	too_deep int // smallest depth whose search needed more than depth_bfs_max_states states, 0 if none has
+}
+
+/**
+ * Returns a random puzzle whose optimal solution is exactly depth moves, without keeping the search
+ * for later puzzles. See DepthGenerator.Puzzle
+ **/
+func NewPuzzleDepth(ctx context.Context, goal *Goal, depth int, r *rand.Rand) (Puzzle, error) {
+	return NewDepthGenerator().Puzzle(ctx, goal, depth, r)
+}
+
+/**
+ * Returns a random puzzle whose optimal solution is exactly depth moves.
+ * If the states up to that depth are few enough, they are found with a breadth first search backwards
+ * from the goal and one is picked uniformly from the last layer. Otherwise random walks are solved
+ * with IDA* and linear conflict until one has the right optimal length.
+ * Returns ctx's error if ctx is done first, as finding a puzzle can take a while
+ **/
+func (g *DepthGenerator) Puzzle(ctx context.Context, goal *Goal, depth int, r *rand.Rand) (Puzzle, error) {
+	if depth < 0 {
+		return Puzzle{}, fmt.Errorf("depth can't be negative")
+	}
+
+	var solved Puzzle = NewPuzzleSolved(goal)
+	layers, complete := g.goalLayers(ctx, solved, depth)
+	if ctx.Err() != nil {
+		return Puzzle{}, ctx.Err()
+	}
+	if complete {
+		if depth >= len(layers) || len(layers[depth]) == 0 {
+			return Puzzle{}, fmt.Errorf("no size %v puzzle has an optimal solution of %v moves", solved.ShapeStr(), depth)
+		}
+		var p Puzzle = layers[depth][r.Intn(len(layers[depth]))].Copy()
+		p.last_move = None
+		return p, nil
+	}
+
+	// a walk's optimal solution is at most its length and has the same parity,
+	// so walks start at depth moves and get longer when they keep falling short
+	for attempt := 0; attempt < depth_max_attempts; attempt++ {
+		var p Puzzle = solved.Copy()
+		for i := 0; i < depth+2*(attempt/10); i++ {
+			moves := p.getNewMoves()
+			p.makeMove(moves[r.Intn(len(moves))])
+		}
+		p.last_move = None
+
+		result := IDAStarSearch(ctx, p, LinearConflict, search.Limits{})
+		if result.Status == search.Cancelled || result.Status == search.Timeout {
+			return Puzzle{}, ctx.Err()
+		}
+		if result.Status == search.Solved && len(result.Path)-1 == depth {
+			return p, nil
+		}
+	}
+
+	return Puzzle{}, fmt.Errorf("couldn't find a size %v puzzle at depth %v in %v attempts", solved.ShapeStr(), depth, depth_max_attempts)
+}
+
+/**
+ * States at each distance from the goal solved up to depth. The breadth first search is kept for
+ * the generator's next puzzle with the same goal, so trials don't repeat it. complete is false if the search
+ * would be too large or ctx is done first
+ **/
+func (g *DepthGenerator) goalLayers(ctx context.Context, solved Puzzle, depth int) (layers [][]Puzzle, complete bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	cached, ok := g.layers[solved.goal.id]
+	if ok && cached.too_deep != 0 && depth >= cached.too_deep {
+		return nil, false
+	}
+	if n := len(cached.layers); n > 0 && (depth < n || len(cached.layers[n-1]) == 0) { // deep enough or every state found
+		return cached.layers, true
+	}
+
+	layers, complete = bfsLayers(ctx, solved, depth, depth_bfs_max_states)
+	if ctx.Err() != nil {
+		return nil, false
+	}
+	if complete {
+		cached.layers = layers
+	} else {
+		cached.too_deep = depth
+	}
+	g.layers[solved.goal.id] = cached
+	return layers, complete
+}
+
+/**
+ * Breadth first search from start, returning the states at each distance up to maxDepth.
+ * complete is false if more than maxStates states would have to be searched or ctx is done first
+ **/
+func bfsLayers(ctx context.Context, start Puzzle, maxDepth int, maxStates int) (layers [][]Puzzle, complete bool) {
+	var seen = map[search.StateKey]bool{start.Key(): true}
+	layers = [][]Puzzle{{start}}
+
+	for d := 0; d < maxDepth && len(layers[d]) > 0; d++ {
+		var next []Puzzle
+		for i, p := range layers[d] {
+			if i%search.CheckInterval == 0 && ctx.Err() != nil {
+				return nil, false
+			}
+			for _, succ := range p.getSuccessors(false) {
+				key := succ.Key()
+				if !seen[key] {
+					seen[key] = true
+					next = append(next, succ)
+				}
+			}
+			if len(seen) > maxStates {
+				return nil, false
+			}
+		}
+		layers = append(layers, next)
+	}
+
+	return layers, true
+}
@@ -0,0 +1,42 @@
+package puzzle
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"tile-puzzle-ai/search"
+)
+
+func TestNewPuzzleDepth(t *testing.T) {
+	goal, err := NewGoalPreset(3, 3, BlankBottomRight)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// depths out of order, so later puzzles come from a search kept from an earlier one
+	r := rand.New(rand.NewSource(1))
+	var generator *DepthGenerator = NewDepthGenerator()
+	for _, depth := range []int{12, 5, 20, 20, 31} {
+		p, err := generator.Puzzle(context.Background(), goal, depth, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := Solve(context.Background(), p, IDAStar, LinearConflict, search.Limits{}, true)
+		if result.Status != search.Solved || len(result.Path)-1 != depth {
+			t.Errorf("depth %v: %v in %v moves", depth, result.Status, len(result.Path)-1)
+		}
+	}
+	if len(generator.layers) != 1 {
+		t.Errorf("%v searches kept, want 1", len(generator.layers))
+	}
+	if _, err := generator.Puzzle(context.Background(), goal, 32, r); err == nil {
+		t.Errorf("depth 32: no error, the longest optimal solution is 31 moves")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	large, _ := NewGoalPreset(4, 4, BlankTopLeft)
+	if _, err := NewPuzzleDepth(ctx, large, 40, r); err != context.Canceled {
+		t.Errorf("cancelled: got %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+/**
+ * Goal layouts
+ * The goal is the arrangement of tiles that counts as solved. Every puzzle points to its goal,
+ * which is shared by the puzzle and all of its successors. Presets are
+ *   - "blank-top-left": tile i at index i, the default
+ *   - "blank-bottom-right": tiles 1 to n-1 in order with the blank last
+ *   - "spiral": tiles 1 to n-1 clockwise from the top left corner, with the blank where the spiral ends
+ * Any other goal is given as its tiles in row major order
+ **/
+type Goal struct {
+	name   string // preset name, or "custom"
+	height int
+	width  int
+	tiles  []byte   // tile at each index when solved
+	pos    []RowCol // goal position of each tile
+	id     string   // identifies the goal and its shape, for caching tables built for it
+}
+
+const (
+	BlankTopLeft     = "blank-top-left"
+	BlankBottomRight = "blank-bottom-right"
+	Spiral           = "spiral"
+	CustomGoal       = "custom"
+)
+
+var GoalPresets = []string{BlankTopLeft, BlankBottomRight, Spiral}
+
+/**
+ * Returns the goal for a preset name with the given number of rows and columns
+ **/
+func NewGoalPreset(rows int, cols int, name string) (*Goal, error) {
+	var arr []int = make([]int, rows*cols)
+	switch name {
+	case "", BlankTopLeft:
+		name = BlankTopLeft
+		for i := range arr {
+			arr[i] = i
+		}
+
+	case BlankBottomRight:
+		for i := range arr {
+			arr[i] = (i + 1) % len(arr)
+		}
+
+	case Spiral:
+		var top, bottom, left, right int = 0, rows - 1, 0, cols - 1
+		var tile int = 1
+		var place = func(r int, c int) {
+			arr[r*cols+c] = tile % len(arr)
+			tile++
+		}
+		for top <= bottom && left <= right {
+			for c := left; c <= right; c++ {
+				place(top, c)
+			}
+			for r := top + 1; r <= bottom; r++ {
+				place(r, right)
+			}
+			if top < bottom {
+				for c := right - 1; c >= left; c-- {
+					place(bottom, c)
+				}
+			}
+			if left < right {
+				for r := bottom - 1; r > top; r-- {
+					place(r, left)
+				}
+			}
+			top, bottom, left, right = top+1, bottom-1, left+1, right-1
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown goal %q, use one of %v or the goal tiles", name, strings.Join(GoalPresets, ", "))
+	}
+
+	goal, err := NewGoal(rows, cols, arr)
+	if err != nil {
+		return nil, err
+	}
+	goal.name = name
+	return goal, nil
+}
+
+/**
+ * Returns a custom goal with the given tiles in row major order
+ **/
+func NewGoal(rows int, cols int, arr []int) (*Goal, error) {
+	if err := CheckPuzzleArr(rows, cols, arr); err != nil {
+		return nil, err
+	}
+
+	var goal = &Goal{
+		name:   CustomGoal,
+		height: rows,
+		width:  cols,
+		tiles:  make([]byte, len(arr)),
+		pos:    make([]RowCol, len(arr)),
+	}
+	for i, e := range arr {
+		goal.tiles[i] = byte(e)
+		goal.pos[e] = RowCol{row: i / cols, col: i % cols}
+	}
+	goal.id = fmt.Sprintf("%vx%v:%s", rows, cols, goal.tiles)
+	return goal, nil
+}
+
+/**
+ * Preset name of the goal, or "custom" for goals given as tiles
+ **/
+func (g *Goal) Name() string {
+	return g.name
+}
+
+/**
+ * Goal as written in the log and in commands, the preset name or the tiles of each row separated by /
+ **/
+func (g *Goal) LayoutStr() string {
+	if g.name != CustomGoal {
+		return g.name
+	}
+
+	var rows []string
+	for r := 0; r < g.height; r++ {
+		var fields []string
+		for _, e := range g.tiles[r*g.width : (r+1)*g.width] {
+			fields = append(fields, fmt.Sprint(e))
+		}
+		rows = append(rows, strings.Join(fields, " "))
+	}
+	return strings.Join(rows, " / ")
+}
+
+/**
+ * Goal given in the config, either a preset name, an array of tiles or a string of rows like an initial state.
+ * The zero value is the default goal
+ **/
+type GoalLayout struct {
+	preset string
+	tiles  []int
+	rows   int // rows and cols are only known when the tiles are given as a string
+	cols   int
+}
+
+func (l *GoalLayout) UnmarshalJSON(data []byte) error {
+	var arr []int
+	if err := json.Unmarshal(data, &arr); err == nil {
+		l.tiles = arr
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("goal must be one of %v, an array of tiles or a string like \"1 2 3 / 8 0 4 / 7 6 5\"", strings.Join(GoalPresets, ", "))
+	}
+
+	layout, err := ParseGoalLayout(str)
+	if err != nil {
+		return err
+	}
+	*l = layout
+	return nil
+}
+
+/**
+ * Parses a preset name or the goal tiles written as rows separated by /
+ **/
+func ParseGoalLayout(str string) (GoalLayout, error) {
+	for _, preset := range GoalPresets {
+		if str == preset {
+			return GoalLayout{preset: str}, nil
+		}
+	}
+
+	arr, rows, cols, err := ParsePuzzleStr(str)
+	if err != nil {
+		return GoalLayout{}, fmt.Errorf("goal %q is not one of %v and can't be read as tiles: %v", str, strings.Join(GoalPresets, ", "), err)
+	}
+	return GoalLayout{tiles: arr, rows: rows, cols: cols}, nil
+}
+
+/**
+ * Shape of a goal given as tiles. A string gives its rows, an array is taken to be square.
+ * Returns 0, 0 for presets, which fit any shape
+ **/
+func (l GoalLayout) Shape() (rows int, cols int) {
+	if l.rows != 0 {
+		return l.rows, l.cols
+	}
+	if l.tiles != nil {
+		_, len := IsSquare(len(l.tiles))
+		return len, len
+	}
+	return 0, 0
+}
+
+func (l GoalLayout) IsSet() bool {
+	return l.preset != "" || l.tiles != nil
+}
+
+/**
+ * Returns the goal of this layout for a board with the given number of rows and columns
+ **/
+func (l GoalLayout) Goal(rows int, cols int) (*Goal, error) {
+	if l.tiles == nil {
+		return NewGoalPreset(rows, cols, l.preset)
+	}
+
+	if l.rows != 0 && (l.rows != rows || l.cols != cols) {
+		return nil, fmt.Errorf("goal is a %vx%v puzzle but the input is %vx%v", l.rows, l.cols, rows, cols)
+	}
+	return NewGoal(rows, cols, l.tiles)
+}
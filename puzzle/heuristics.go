@@ -0,0 +1,169 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type Heuristic func(p Puzzle) float32
+
+var heuristics = [...]Heuristic{MisplacedTiles, ManhattanDistance, MaxsortSwaps, EuclideanDistance, LinearConflict, nil, WalkingDistance, WalkingDistanceLinearConflict}
+
+/**
+ * Heuristics are numbered 1 to NumHeuristics
+ **/
+const NumHeuristics = len(heuristics)
+
+/**
+ * Heuristic number of the pattern database, which is loaded from a file so has no entry in heuristics
+ **/
+const PDBHeuristic = 6
+
+/**
+ * Returns the heuristic for a number from the config, pdb is only used by the pattern database heuristic
+ **/
+func GetHeuristic(heuristic_num int, pdb *PatternDB) (Heuristic, error) {
+	if heuristic_num < 1 || heuristic_num > NumHeuristics {
+		return nil, fmt.Errorf("unknown heuristic %v, heuristics are 1 to %v", heuristic_num, NumHeuristics)
+	}
+	if heuristic_num == PDBHeuristic {
+		if pdb == nil {
+			return nil, errors.New("the pattern database heuristic needs a pattern database")
+		}
+		return pdb.Heuristic, nil
+	}
+	return heuristics[heuristic_num-1], nil
+}
+
+func MisplacedTiles(p Puzzle) float32 {
+	var cost float32 = 0
+	for i := 0; i < p.Size(); i++ {
+		if e := p.GetN(i); (e != 0) && (e != p.getGoalN(i)) {
+			cost++
+		}
+	}
+
+	return cost
+}
+
+func ManhattanDistance(p Puzzle) float32 {
+	var cost float32 = 0
+	for r := 0; r < p.Rows(); r++ {
+		for c := 0; c < p.Cols(); c++ {
+			goalPos := p.getGoalPos(p.get(RowCol{row: r, col: c}))
+			cost += float32(math.Abs(float64(r - goalPos.row)))
+			cost += float32(math.Abs(float64(c - goalPos.col)))
+		}
+	}
+	return cost
+}
+
+/**
+ * Maxsort swaps, sorting each tile by the index of its goal position
+ **/
+func MaxsortSwaps(p Puzzle) float32 {
+	var cost float32 = 0
+	var arr []int = make([]int, p.Size())
+	for i := range arr {
+		arr[i] = p.getGoalPos(p.GetN(i)).toN(p.Cols())
+	}
+
+	for i := len(arr) - 1; i > 0; i-- {
+		max := 0
+		for j := 0; j <= i; j++ {
+			if arr[max] < arr[j] {
+				max = j
+			}
+		}
+		if i != max {
+			temp := arr[i]
+			arr[i] = arr[max]
+			arr[max] = temp
+			cost++
+		}
+	}
+
+	return cost
+}
+
+func EuclideanDistance(p Puzzle) float32 {
+	var cost float32 = 0
+	for r := 0; r < p.Rows(); r++ {
+		for c := 0; c < p.Cols(); c++ {
+			goalPos := p.getGoalPos(p.get(RowCol{row: r, col: c}))
+			cost += euclidean_dist(goalPos.row-r, goalPos.col-c)
+		}
+	}
+	return float32(cost)
+}
+
+/**
+ * Linear conflict: manhattan distance of every tile except the blank, plus 2 moves for each tile
+ * that has to leave its goal row or column so the other tiles in that line can pass each other.
+ * The tiles that stay are the longest run already in goal order, so the count is the minimum and
+ * remains admissible when more than two tiles in a line are reversed
+ **/
+func LinearConflict(p Puzzle) float32 {
+	var cost float32 = 0
+	for n := 0; n < p.Size(); n++ {
+		e := p.GetN(n)
+		if e == 0 {
+			continue
+		}
+		pos := p.nToCoord(n)
+		goalPos := p.getGoalPos(e)
+		cost += float32(math.Abs(float64(pos.row - goalPos.row)))
+		cost += float32(math.Abs(float64(pos.col - goalPos.col)))
+	}
+
+	var line []int = make([]int, 0, p.Rows()+p.Cols())
+	for r := 0; r < p.Rows(); r++ { // tiles in their goal row
+		line = line[:0]
+		for c := 0; c < p.Cols(); c++ {
+			if e := p.get(RowCol{row: r, col: c}); e != 0 && p.getGoalPos(e).row == r {
+				line = append(line, p.getGoalPos(e).col)
+			}
+		}
+		cost += float32(2 * lineConflicts(line))
+	}
+
+	for c := 0; c < p.Cols(); c++ { // tiles in their goal column
+		line = line[:0]
+		for r := 0; r < p.Rows(); r++ {
+			if e := p.get(RowCol{row: r, col: c}); e != 0 && p.getGoalPos(e).col == c {
+				line = append(line, p.getGoalPos(e).row)
+			}
+		}
+		cost += float32(2 * lineConflicts(line))
+	}
+
+	return cost
+}
+
+/**
+ * returns how many tiles must be removed from a line so the rest are in goal order,
+ * goals holds the goal index along the line of each tile in the order they appear
+ **/
+func lineConflicts(goals []int) int {
+	if len(goals) < 2 {
+		return 0
+	}
+
+	// longest increasing subsequence ending at each tile
+	var lis []int = make([]int, len(goals))
+	var longest int = 0
+	for i := range goals {
+		lis[i] = 1
+		for j := 0; j < i; j++ {
+			if goals[j] < goals[i] && lis[j]+1 > lis[i] {
+				lis[i] = lis[j] + 1
+			}
+		}
+		if lis[i] > longest {
+			longest = lis[i]
+		}
+	}
+
+	return len(goals) - longest
+}
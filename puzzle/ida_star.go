@@ -0,0 +1,155 @@
+package puzzle
+
+import (
+	"context"
+	"math"
+	"tile-puzzle-ai/search"
+	"time"
+)
+
+/**
+ * Iterative deepening A*
+ * Runs depth first searches bounded by f = g + h, raising the bound to the smallest f that
+ * exceeded it after each iteration. Only the current path is kept in memory, the puzzle is
+ * moved in place and moves are undone on the way back up.
+ * Successors always skip the move that undoes the previous one using getNewMoves.
+ *
+ * The result has the bound of every iteration, and the nodes evaluated and generated over all iterations.
+ * The search stops when ctx is done or a limit is reached, with the metrics so far. Only the stored
+ * node limit applies to memory, counting the nodes on the current path. Progress is reported
+ * if ctx was made by search.WithProgress
+ **/
+func IDAStarSearch(ctx context.Context, initial Puzzle, h Heuristic, limits search.Limits) Result {
+	start := time.Now()
+	var thresholds []float32
+	var evaluated, generated int = 0, 0
+	var bestF, deepestG float32 = 0, 0
+	var stored int = 1 // longest path so far
+	var bestH float32 = h(initial)
+	var progress = search.NewProgressTicker(ctx)
+	var result = func(status search.Status, path []Puzzle) Result {
+		return Result{
+			Status:     status,
+			Path:       path,
+			Duration:   time.Since(start),
+			Expanded:   evaluated,
+			Generated:  generated,
+			Thresholds: thresholds,
+			BestF:      bestF,
+			DeepestG:   deepestG,
+			Stored:     stored,
+		}
+	}
+
+	var p Puzzle = initial.Copy()
+	p.last_move = None
+
+	var moves []Move          // moves along the current path
+	var stopped search.Status // why the search stopped early, empty while it's running
+
+	var dfs func(g int, bound float32) (float32, bool)
+	dfs = func(g int, bound float32) (float32, bool) {
+		f := float32(g) + h(p)
+		if f > bound {
+			return f, false
+		}
+
+		if p.IsSolved() {
+			return f, true
+		}
+
+		// stopping conditions
+		if evaluated%search.CheckInterval == 0 {
+			if ctx.Err() != nil {
+				stopped = search.ContextStatus(ctx)
+				return f, false
+			}
+			if progress.Due() {
+				progress.Report(search.Progress{
+					Elapsed:   time.Since(start),
+					Expanded:  evaluated,
+					Generated: generated,
+					Open:      len(moves) + 1,
+					Bound:     bound,
+					BestH:     bestH,
+				})
+			}
+		}
+		if limits.Nodes > 0 && evaluated >= limits.Nodes {
+			stopped = search.NodeLimit
+			return f, false
+		}
+		if limits.Stored > 0 && len(moves)+1 >= limits.Stored {
+			stopped = search.MemoryLimit
+			return f, false
+		}
+
+		evaluated++
+		if f > bestF {
+			bestF = f
+		}
+		if float32(g) > deepestG {
+			deepestG = float32(g)
+		}
+		if f-float32(g) < bestH {
+			bestH = f - float32(g)
+		}
+		if len(moves)+1 > stored {
+			stored = len(moves) + 1
+		}
+		var minF float32 = float32(math.Inf(1))
+		for _, m := range p.getNewMoves() {
+			generated++
+			last := p.last_move
+			p.makeMove(m)
+			moves = append(moves, m)
+
+			t, found := dfs(g+1, bound)
+			if found {
+				return t, true
+			}
+
+			moves = moves[:len(moves)-1]
+			p.makeMove(m.opposite())
+			p.last_move = last
+
+			if stopped != "" {
+				return t, false
+			}
+			if t < minF {
+				minF = t
+			}
+		}
+		return minF, false
+	}
+
+	var bound float32 = h(p)
+	for {
+		thresholds = append(thresholds, bound)
+		next, found := dfs(0, bound)
+
+		if found {
+			return result(search.Solved, idaPath(initial, moves))
+		} else if stopped != "" {
+			return result(stopped, make([]Puzzle, 0))
+		} else if math.IsInf(float64(next), 1) { // nothing left beyond the bound
+			return result(search.Unsolvable, make([]Puzzle, 0))
+		}
+
+		bound = next
+	}
+}
+
+/**
+ * replays moves from the initial state, returning the states goal first
+ **/
+func idaPath(initial Puzzle, moves []Move) []Puzzle {
+	var path []Puzzle = make([]Puzzle, len(moves)+1)
+	var p Puzzle = initial.Copy()
+	path[len(moves)] = p.Copy()
+	for i, m := range moves {
+		p.makeMove(m)
+		path[len(moves)-1-i] = p.Copy()
+	}
+	return path
+}
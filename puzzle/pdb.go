@@ -0,0 +1,409 @@
+package puzzle
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+/**
+ * Additive disjoint pattern database
+ * The tiles (not the blank) are split into disjoint patterns. For each pattern a table holds
+ * the number of moves of pattern tiles needed to bring them from any placement to their goal
+ * positions, with the blank free to move past the tiles outside the pattern. Every move moves
+ * exactly one tile so the tables can be summed and stay admissible.
+ * Tables are indexed by the rank of the positions of the pattern tiles as a k-permutation of the board.
+ **/
+type PatternDB struct {
+	height   int
+	width    int
+	goal     []byte  // goal state the tables were built for, row major
+	patterns [][]int // tile values in each pattern
+	tables   [][]byte
+}
+
+const pdb_magic = "TPDB"
+const pdb_version = 2
+const PDBExt = ".pdb"
+const pdb_max_states = 1 << 26 // most states searched per pattern when picking default patterns
+const pdb_unset = 0xFF
+
+/**
+ * Builds the tables for each pattern with a breadth first search backwards from the goal.
+ * Building a pattern of k tiles searches n!/(n-k-1)! states with a byte each, so 6-6-3 is
+ * the largest practical partition for 4x4 boards
+ **/
+func BuildPatternDB(goal Puzzle, patterns [][]int) *PatternDB {
+	var db = &PatternDB{
+		height:   goal.Rows(),
+		width:    goal.Cols(),
+		goal:     goal.Copy().tiles,
+		patterns: patterns,
+		tables:   make([][]byte, len(patterns)),
+	}
+
+	for i, pattern := range patterns {
+		db.tables[i] = db.buildTable(goal, pattern)
+	}
+
+	return db
+}
+
+func (db *PatternDB) buildTable(goal Puzzle, pattern []int) []byte {
+	var n int = goal.Size()
+	var k int = len(pattern)
+
+	// the search tracks the blank as an extra tile at pos[k], but the stored table
+	// keeps the fewest moves over every blank position
+	var dist []byte = make([]byte, numPermutations(n, k+1))
+	var table []byte = make([]byte, numPermutations(n, k))
+	for i := range dist {
+		dist[i] = pdb_unset
+	}
+	for i := range table {
+		table[i] = pdb_unset
+	}
+
+	var pos []int = make([]int, k+1)
+	for i, tile := range pattern {
+		pos[i] = goalIndex(goal, tile)
+	}
+	pos[k] = goalIndex(goal, 0)
+
+	var start = rankPositions(pos, n)
+	dist[start] = 0
+	var cur []uint32 = []uint32{uint32(start)}
+	var next []uint32
+	var owner []int = make([]int, n) // pattern tile on each cell, -1 for none
+
+	// moves of the blank past other tiles are free, moving a pattern tile costs 1,
+	// so states are searched level by level with free moves added to the current level
+	for depth := byte(0); len(cur) > 0; depth++ {
+		if depth == pdb_unset-1 {
+			panic("pattern database depth does not fit in a byte")
+		}
+
+		for i := 0; i < len(cur); i++ {
+			var idx = int(cur[i])
+			if dist[idx] < depth { // already searched at a lower depth
+				continue
+			}
+
+			unrankPositions(idx, n, pos)
+			if t := rankPositions(pos[:k], n); table[t] == pdb_unset {
+				table[t] = depth
+			}
+
+			for c := range owner {
+				owner[c] = -1
+			}
+			for j, p := range pos[:k] {
+				owner[p] = j
+			}
+
+			var blank int = pos[k]
+			for _, nb := range goal.neighbours(goal.nToCoord(blank)) {
+				cell := nb.toN(goal.Cols())
+				if j := owner[cell]; j == -1 { // blank moves past a tile outside the pattern
+					pos[k] = cell
+					if s := rankPositions(pos, n); dist[s] > depth {
+						dist[s] = depth
+						cur = append(cur, uint32(s))
+					}
+				} else { // pattern tile moves into the blank
+					pos[k] = cell
+					pos[j] = blank
+					if s := rankPositions(pos, n); dist[s] > depth+1 {
+						dist[s] = depth + 1
+						next = append(next, uint32(s))
+					}
+					pos[j] = cell
+				}
+				pos[k] = blank
+			}
+		}
+
+		cur, next = next, cur[:0]
+	}
+
+	return table
+}
+
+/**
+ * The heuristic given by the database, the sum of every pattern's table
+ **/
+func (db *PatternDB) Heuristic(p Puzzle) float32 {
+	var loc []int = make([]int, p.Size()) // position of each tile
+	for i := 0; i < p.Size(); i++ {
+		loc[p.GetN(i)] = i
+	}
+
+	var cost float32 = 0
+	var pos []int = make([]int, 0, p.Size())
+	for i, pattern := range db.patterns {
+		pos = pos[:0]
+		for _, tile := range pattern {
+			pos = append(pos, loc[tile])
+		}
+		cost += float32(db.tables[i][rankPositions(pos, p.Size())])
+	}
+	return cost
+}
+
+/**
+ * Returns an error if the database was not built for the board and goal of p
+ **/
+func (db *PatternDB) CheckCompatible(goal Puzzle) error {
+	if db.height != goal.Rows() || db.width != goal.Cols() {
+		return fmt.Errorf("pattern database is for %vx%v puzzles, not %vx%v", db.height, db.width, goal.Rows(), goal.Cols())
+	}
+	if !bytes.Equal(db.goal, goal.tiles) {
+		return errors.New("pattern database was built for a different goal state")
+	}
+	return nil
+}
+
+/**
+ * Splits the tiles into patterns of neighbouring goal positions, each as large as possible
+ * without searching more than pdb_max_states states to build it
+ **/
+func DefaultPatterns(goal Puzzle) [][]int {
+	var n int = goal.Size()
+	var k int = 1
+	for k < n-1 && numPermutations(n, k+2) <= pdb_max_states {
+		k++
+	}
+
+	var patterns [][]int
+	var pattern []int
+	for i := 0; i < n; i++ { // tiles in goal order
+		if tile := goal.GetN(i); tile != 0 {
+			pattern = append(pattern, tile)
+		}
+		if len(pattern) == k || (i == n-1 && len(pattern) > 0) {
+			patterns = append(patterns, pattern)
+			pattern = nil
+		}
+	}
+	return patterns
+}
+
+/**
+ * Checks that patterns only hold tiles of the puzzle, don't contain the blank and don't overlap
+ **/
+func ValidatePatterns(n int, patterns [][]int) error {
+	var seen []bool = make([]bool, n)
+	for _, pattern := range patterns {
+		if len(pattern) == 0 {
+			return errors.New("empty pattern")
+		}
+		for _, tile := range pattern {
+			if tile <= 0 || tile >= n {
+				return fmt.Errorf("tile %v is not a tile of the puzzle", tile)
+			}
+			if seen[tile] {
+				return fmt.Errorf("tile %v is in more than one pattern", tile)
+			}
+			seen[tile] = true
+		}
+	}
+	return nil
+}
+
+/**
+ * File format, gzip compressed:
+ * magic, version, rows, cols, goal tiles, number of patterns,
+ * then for each pattern the number of tiles, the tiles and the table
+ **/
+func (db *PatternDB) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	w := bufio.NewWriter(zw)
+
+	w.WriteString(pdb_magic)
+	w.WriteByte(pdb_version)
+	w.WriteByte(byte(db.height))
+	w.WriteByte(byte(db.width))
+	w.Write(db.goal)
+	w.WriteByte(byte(len(db.patterns)))
+	for i, pattern := range db.patterns {
+		w.WriteByte(byte(len(pattern)))
+		for _, tile := range pattern {
+			w.WriteByte(byte(tile))
+		}
+		binary.Write(w, binary.LittleEndian, uint32(len(db.tables[i])))
+		w.Write(db.tables[i])
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func LoadPatternDB(filename string) (*PatternDB, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("%v is not a pattern database: %v", filename, err)
+	}
+	r := bufio.NewReader(zr)
+
+	var header [len(pdb_magic) + 3]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil || string(header[:len(pdb_magic)]) != pdb_magic {
+		return nil, fmt.Errorf("%v is not a pattern database", filename)
+	}
+	if header[len(pdb_magic)] != pdb_version {
+		return nil, fmt.Errorf("%v has unsupported version %v", filename, header[len(pdb_magic)])
+	}
+
+	var db = &PatternDB{height: int(header[len(pdb_magic)+1]), width: int(header[len(pdb_magic)+2])}
+	var n int = db.height * db.width
+	db.goal = make([]byte, n)
+	if _, err := io.ReadFull(r, db.goal); err != nil {
+		return nil, fmt.Errorf("%v is truncated", filename)
+	}
+
+	numPatterns, err := r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("%v is truncated", filename)
+	}
+
+	for i := 0; i < int(numPatterns); i++ {
+		k, err := r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%v is truncated", filename)
+		}
+		var pattern []int = make([]int, k)
+		for j := range pattern {
+			tile, err := r.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("%v is truncated", filename)
+			}
+			pattern[j] = int(tile)
+		}
+
+		var length uint32
+		if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+			return nil, fmt.Errorf("%v is truncated", filename)
+		}
+		if int(length) != numPermutations(n, int(k)) {
+			return nil, fmt.Errorf("%v has a table of the wrong length", filename)
+		}
+		var table []byte = make([]byte, length)
+		if _, err := io.ReadFull(r, table); err != nil {
+			return nil, fmt.Errorf("%v is truncated", filename)
+		}
+
+		db.patterns = append(db.patterns, pattern)
+		db.tables = append(db.tables, table)
+	}
+
+	if err := ValidatePatterns(n, db.patterns); err != nil {
+		return nil, fmt.Errorf("%v: %v", filename, err)
+	}
+
+	return db, nil
+}
+
+func ParsePatterns(s string) ([][]int, error) {
+	var patterns [][]int
+	for _, group := range strings.Split(s, "/") {
+		var pattern []int
+		for _, field := range strings.Split(group, ",") {
+			tile, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil {
+				return nil, fmt.Errorf("invalid tile %q in patterns", field)
+			}
+			pattern = append(pattern, tile)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+/**
+ * index of tile in the goal state
+ **/
+func goalIndex(goal Puzzle, tile int) int {
+	for i := 0; i < goal.Size(); i++ {
+		if goal.GetN(i) == tile {
+			return i
+		}
+	}
+	panic("tile not in goal state")
+}
+
+/**
+ * n! / (n-k)!, the number of ways to place k distinct tiles on n cells
+ **/
+func numPermutations(n int, k int) int {
+	var count int = 1
+	for i := 0; i < k; i++ {
+		count *= n - i
+	}
+	return count
+}
+
+/**
+ * Ranks distinct positions in [0, n) as a k-permutation, giving a dense index in [0, n!/(n-k)!)
+ **/
+func rankPositions(pos []int, n int) int {
+	var rank int = 0
+	for i, p := range pos {
+		smaller := 0
+		for _, q := range pos[:i] {
+			if q < p {
+				smaller++
+			}
+		}
+		rank = rank*(n-i) + p - smaller
+	}
+	return rank
+}
+
+/**
+ * Inverse of rankPositions, writes the positions into pos
+ **/
+func unrankPositions(rank int, n int, pos []int) {
+	var k int = len(pos)
+	for i := k - 1; i >= 0; i-- { // digits in mixed radix n, n-1, ..., n-k+1
+		pos[i] = rank % (n - i)
+		rank /= n - i
+	}
+
+	// each digit counts the positions below it not used by earlier tiles
+	for i, digit := range pos {
+		var p int = digit
+		for {
+			used := 0
+			for _, q := range pos[:i] {
+				if q <= p {
+					used++
+				}
+			}
+			if digit+used == p {
+				break
+			}
+			p = digit + used
+		}
+		pos[i] = p
+	}
+}
@@ -0,0 +1,41 @@
+package puzzle
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"tile-puzzle-ai/search"
+)
+
+func TestPatternDBAStarOptimal(t *testing.T) {
+	goal, err := NewGoalPreset(3, 4, BlankBottomRight)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := BuildPatternDB(NewPuzzleSolved(goal), [][]int{{1, 2, 5, 6}, {3, 4, 7, 8}, {9, 10, 11}})
+
+	// the database takes the fewest moves over every blank position, so it isn't consistent and
+	// A* finds a longer path on boards 128 and 142 unless it reopens closed nodes
+	r := rand.New(rand.NewSource(1))
+	var boards []Puzzle
+	for i := 0; i <= 142; i++ {
+		boards = append(boards, NewPuzzleUniform(goal, r))
+	}
+
+	for _, i := range []int{0, 1, 2, 128, 142} {
+		p := boards[i]
+		for _, skip_reverse := range []bool{true, false} {
+			a := Solve(context.Background(), p, AStar, db.Heuristic, search.Limits{}, skip_reverse)
+			ida := Solve(context.Background(), p, IDAStar, LinearConflict, search.Limits{}, true)
+			if a.Status != search.Solved || ida.Status != search.Solved {
+				t.Fatalf("board %v: status %v and %v", i, a.Status, ida.Status)
+			}
+			if !VerifySolution(a.Path) {
+				t.Fatalf("board %v: invalid solution", i)
+			}
+			if len(a.Path) != len(ida.Path) {
+				t.Errorf("board %v: A* with the pattern database found %v moves, the optimal is %v", i, len(a.Path)-1, len(ida.Path)-1)
+			}
+		}
+	}
+}
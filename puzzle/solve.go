@@ -0,0 +1,72 @@
+package puzzle
+
+import (
+	"context"
+	"tile-puzzle-ai/search"
+)
+
+/**
+ * Search algorithm used to solve a puzzle
+ **/
+type Algorithm string
+
+const (
+	AStar   Algorithm = "a*"
+	IDAStar Algorithm = "ida*"
+)
+
+/**
+ * Outcome of solving a puzzle, the path is stored goal first
+ **/
+type Result = search.Result[Puzzle]
+
+/**
+ * Solves a puzzle with the given algorithm. Puzzles that can't reach the goal are reported
+ * unsolvable without searching, rather than exhausting half the state space to find out.
+ * Give ctx a deadline for a time limit, the search stops with the metrics so far when ctx is done
+ * or a limit is reached. skip_reverse only changes A*, IDA* always skips the move that undoes the previous one
+ **/
+func Solve(ctx context.Context, initial Puzzle, algorithm Algorithm, h Heuristic, limits search.Limits, skip_reverse bool) Result {
+	if !initial.IsSolvable() {
+		return Result{Status: search.Unsolvable, Path: make([]Puzzle, 0)}
+	}
+
+	if algorithm == IDAStar {
+		return IDAStarSearch(ctx, initial, h, limits)
+	}
+	return search.AStar[Puzzle](ctx, initial, h, limits, skip_reverse)
+}
+
+/**
+ * Checks a solution path from Solve ends at the goal and every state is one move from the one before it
+ **/
+func VerifySolution(path []Puzzle) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	if !path[0].IsSolved() {
+		return false
+	}
+
+	var cur, prev Puzzle
+	for i := range path {
+		if i >= len(path)-1 {
+			break
+		}
+		cur = path[i]
+		prev = path[i+1]
+		if !cur.isSuccessorTo(prev) {
+			return false
+		}
+	}
+
+	return true
+}
+
+/**
+ * checks a solution path from Solve has the known optimal length
+ **/
+func VerifyOptimal(path []Puzzle, optimal int) bool {
+	return len(path)-1 == optimal
+}
@@ -0,0 +1,317 @@
+package puzzle
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/**
+ * Benchmark instance suites
+ * Built in suites are "8-puzzle", 8-puzzles chosen evenly from every state at each optimal depth,
+ * "random", uniformly random solvable puzzles of any size seeded by the seed given and index, and
+ * "korf100", Korf's 100 15-puzzles with their optimal lengths. Any other name is read from <name>.suite.
+ * Instances are solved towards the input's goal, so the optimal lengths in a suite file only hold
+ * for the goal they were found for
+ **/
+
+/**
+ * A puzzle from a suite, numbered from 1 within its suite
+ **/
+type Instance struct {
+	Suite   string
+	Number  int
+	Goal    *Goal
+	Tiles   []int
+	Optimal int // optimal solution length, -1 if unknown
+}
+
+const suite_ext = ".suite"
+const eight_puzzle_per_depth = 10
+
+var eight_puzzle_suites = map[string][]Instance{} // built the first time each goal is used, by goal id
+var eight_puzzle_lock sync.Mutex
+
+/**
+ * Returns instances first to last (inclusive, numbered from 1) of a suite, with goals from layout.
+ * last = 0 means the end of the suite, rows, cols and seed are only used by the random suite
+ **/
+func GetSuite(name string, layout GoalLayout, rows int, cols int, first int, last int, seed int64) ([]Instance, error) {
+	if first <= 0 {
+		first = 1
+	}
+
+	var all []Instance
+	switch name {
+	case "random":
+		if last == 0 {
+			return nil, fmt.Errorf("the random suite has no end, set last")
+		}
+		if rows < 2 || cols < 2 {
+			return nil, fmt.Errorf("the random suite needs at least 2 rows and columns")
+		}
+		goal, err := layout.Goal(rows, cols)
+		if err != nil {
+			return nil, err
+		}
+		var instances []Instance
+		for number := first; number <= last; number++ {
+			instances = append(instances, randomInstance(goal, number, seed))
+		}
+		return instances, nil
+
+	case "8-puzzle":
+		goal, err := layout.Goal(3, 3)
+		if err != nil {
+			return nil, err
+		}
+		eight_puzzle_lock.Lock()
+		if _, ok := eight_puzzle_suites[goal.id]; !ok {
+			eight_puzzle_suites[goal.id] = buildEightPuzzleSuite(goal)
+		}
+		all = eight_puzzle_suites[goal.id]
+		eight_puzzle_lock.Unlock()
+
+	case "korf100":
+		var err error
+		if all, err = korf100Suite(layout); err != nil {
+			return nil, err
+		}
+
+	default:
+		var err error
+		if all, err = readSuite(name, layout); err != nil {
+			return nil, err
+		}
+	}
+
+	return suiteRange(name, all, first, last)
+}
+
+/**
+ * Checks instances first to last of a suite exist and returns the goals they are solved towards,
+ * without generating the random suite or building the 8-puzzle suite, so configs can be checked quickly.
+ * The range of the 8-puzzle suite isn't checked, as its size depends on the goal
+ **/
+func SuiteGoals(name string, layout GoalLayout, rows int, cols int, first int, last int) ([]*Goal, error) {
+	if first <= 0 {
+		first = 1
+	}
+
+	var all []Instance
+	var err error
+	switch name {
+	case "random":
+		if last == 0 {
+			return nil, fmt.Errorf("the random suite has no end, set last")
+		}
+		if rows < 2 || cols < 2 {
+			return nil, fmt.Errorf("the random suite needs at least 2 rows and columns")
+		}
+		goal, err := layout.Goal(rows, cols)
+		if err != nil {
+			return nil, err
+		}
+		return []*Goal{goal}, nil
+
+	case "8-puzzle":
+		goal, err := layout.Goal(3, 3)
+		if err != nil {
+			return nil, err
+		}
+		return []*Goal{goal}, nil
+
+	case "korf100":
+		all, err = korf100Suite(layout)
+	default:
+		all, err = readSuite(name, layout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if all, err = suiteRange(name, all, first, last); err != nil {
+		return nil, err
+	}
+
+	var goals []*Goal
+	var seen = map[string]bool{}
+	for _, instance := range all {
+		if !seen[instance.Goal.id] {
+			seen[instance.Goal.id] = true
+			goals = append(goals, instance.Goal)
+		}
+	}
+	return goals, nil
+}
+
+/**
+ * Instances first to last of a suite, last = 0 or past the end meaning the end of the suite
+ **/
+func suiteRange(name string, all []Instance, first int, last int) ([]Instance, error) {
+	if last != 0 && first > last {
+		return nil, fmt.Errorf("first instance %v is after the last %v", first, last)
+	}
+	if last == 0 || last > len(all) {
+		last = len(all)
+	}
+	if first > last {
+		return nil, fmt.Errorf("suite %v has %v instances, can't start at %v", name, len(all), first)
+	}
+	return all[first-1 : last], nil
+}
+
+/**
+ * Instance number of the random suite, each instance has its own seed so it doesn't depend on the range asked for
+ **/
+func randomInstance(goal *Goal, number int, seed int64) Instance {
+	r := rand.New(rand.NewSource(seed + int64(number)))
+	p := NewPuzzleUniform(goal, r)
+
+	var tiles []int = make([]int, p.Size())
+	for i := range tiles {
+		tiles[i] = p.GetN(i)
+	}
+	return Instance{Suite: "random", Number: number, Goal: goal, Tiles: tiles, Optimal: -1}
+}
+
+/**
+ * Breadth first search over every 8-puzzle state from the goal, then picks states spread
+ * evenly through each depth so every optimal solution length from 0 to 31 is covered
+ **/
+func buildEightPuzzleSuite(goal *Goal) []Instance {
+	levels, _ := bfsLayers(context.Background(), NewPuzzleSolved(goal), math.MaxInt32, depth_bfs_max_states)
+
+	var instances []Instance
+	for d, level := range levels {
+		var count int = eight_puzzle_per_depth
+		if len(level) < count {
+			count = len(level)
+		}
+		for j := 0; j < count; j++ {
+			p := level[j*len(level)/count]
+			var tiles []int = make([]int, p.Size())
+			for i := range tiles {
+				tiles[i] = p.GetN(i)
+			}
+			instances = append(instances, Instance{
+				Suite:   "8-puzzle",
+				Number:  len(instances) + 1,
+				Goal:    goal,
+				Tiles:   tiles,
+				Optimal: d,
+			})
+		}
+	}
+	return instances
+}
+
+/**
+ * Korf's 100 15-puzzles, whose optimal lengths are only known for the blank-top-left goal they were solved for
+ **/
+func korf100Suite(layout GoalLayout) ([]Instance, error) {
+	goal, err := layout.Goal(4, 4)
+	if err != nil {
+		return nil, err
+	}
+	korf_goal, _ := NewGoalPreset(4, 4, BlankTopLeft)
+
+	var instances []Instance
+	for i, line := range korf100 {
+		instance, err := parseSuiteLine(line, layout)
+		if err != nil {
+			panic(fmt.Sprintf("korf100 instance %v: %v", i+1, err))
+		}
+		instance.Suite, instance.Number = "korf100", i+1
+		if goal.id != korf_goal.id { // compared by tiles, so the same goal given as tiles keeps the lengths
+			instance.Optimal = -1
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+/**
+ * Reads name.suite, one puzzle per line with 0 as the blank, optionally followed by the optimal solution length.
+ * See parseSuiteLine for how boards are written. Blank lines and lines starting with # are skipped
+ **/
+func readSuite(name string, layout GoalLayout) ([]Instance, error) {
+	f, err := os.Open(name + suite_ext)
+	if err != nil {
+		return nil, fmt.Errorf("unknown suite %v, no built in suite has that name and %v", name, err)
+	}
+	defer f.Close()
+
+	var instances []Instance
+	var scanner = bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		var text string = strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		instance, err := parseSuiteLine(text, layout)
+		if err != nil {
+			return nil, fmt.Errorf("%v%v line %v: %v", name, suite_ext, line, err)
+		}
+		instance.Suite, instance.Number = name, len(instances)+1
+		instances = append(instances, instance)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return nil, fmt.Errorf("%v%v has no instances", name, suite_ext)
+	}
+	return instances, nil
+}
+
+/**
+ * Parses one puzzle of a suite, optionally followed by the optimal solution length. Square boards can
+ * be given as their tiles in row major order, other boards with their rows separated by / like generate prints them
+ **/
+func parseSuiteLine(text string, layout GoalLayout) (Instance, error) {
+	var lines []string = strings.Split(text, "/")
+	var last []string = strings.Fields(lines[len(lines)-1])
+	var instance = Instance{Optimal: -1}
+
+	// the board has as many tiles in its last row as its first, or is square without rows
+	var tile_count int = len(strings.Fields(lines[0]))
+	if len(lines) == 1 {
+		tile_count = len(last)
+		if square, _ := IsSquare(len(last)); !square {
+			tile_count--
+		}
+	}
+	if len(last) == tile_count+1 && len(last) > 1 { // last number is the optimal length
+		optimal, err := strconv.Atoi(last[len(last)-1])
+		if err != nil {
+			return Instance{}, fmt.Errorf("invalid number %q", last[len(last)-1])
+		}
+		instance.Optimal = optimal
+		lines[len(lines)-1] = strings.Join(last[:len(last)-1], " ")
+	}
+
+	tiles, rows, cols, err := ParsePuzzleStr(strings.Join(lines, "/"))
+	if err != nil {
+		return Instance{}, err
+	}
+	if rows == 1 {
+		_, rows = IsSquare(len(tiles))
+		cols = rows
+	}
+	if err := CheckPuzzleArr(rows, cols, tiles); err != nil {
+		return Instance{}, err
+	}
+	instance.Tiles = tiles
+	if instance.Goal, err = layout.Goal(rows, cols); err != nil {
+		return Instance{}, err
+	}
+	return instance, nil
+}
@@ -0,0 +1,84 @@
+package puzzle
+
+import (
+	"context"
+	"testing"
+	"tile-puzzle-ai/search"
+)
+
+func TestKorf100Suite(t *testing.T) {
+	all, err := GetSuite("korf100", GoalLayout{}, 0, 0, 1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 100 {
+		t.Fatalf("%v instances, want 100", len(all))
+	}
+
+	var total int = 0
+	for _, instance := range all {
+		p, err := NewPuzzle(instance.Goal, instance.Tiles)
+		if err != nil {
+			t.Fatalf("instance %v: %v", instance.Number, err)
+		}
+		if !p.IsSolvable() {
+			t.Errorf("instance %v isn't solvable", instance.Number)
+		}
+		// every move moves the blank one cell, so the length has the parity of its distance from the goal
+		if blank := p.getGoalPos(0); (instance.Optimal-p.zero_loc.row-p.zero_loc.col+blank.row+blank.col)%2 != 0 {
+			t.Errorf("instance %v: optimal length %v has the wrong parity", instance.Number, instance.Optimal)
+		}
+		if h := int(LinearConflict(p)); h > instance.Optimal {
+			t.Errorf("instance %v: optimal length %v is below linear conflict %v", instance.Number, instance.Optimal, h)
+		}
+		total += instance.Optimal
+	}
+	if total != 5305 { // an average of 53.05 moves
+		t.Errorf("optimal lengths add up to %v, want 5305", total)
+	}
+
+	for _, number := range []int{55, 79} { // the two quickest to solve
+		instance := all[number-1]
+		result := Solve(context.Background(), MustNewPuzzle(instance.Goal, instance.Tiles), IDAStar, WalkingDistanceLinearConflict, search.Limits{}, true)
+		if result.Status != search.Solved || !VerifyOptimal(result.Path, instance.Optimal) {
+			t.Errorf("instance %v: %v in %v moves, optimal is %v", number, result.Status, len(result.Path)-1, instance.Optimal)
+		}
+	}
+
+	spiral, err := GetSuite("korf100", GoalLayout{preset: Spiral}, 0, 0, 1, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spiral[0].Optimal != -1 {
+		t.Errorf("optimal length %v given for the spiral goal", spiral[0].Optimal)
+	}
+}
+
+func TestParseSuiteLine(t *testing.T) {
+	var cases = []struct {
+		text       string
+		rows, cols int
+		optimal    int
+	}{
+		{"1 2 0 3", 2, 2, -1},
+		{"8 1 2 0 4 3 7 6 5 9", 3, 3, 9},
+		{"0 4 5 3 / 1 8 2 7 / 9 10 6 11", 3, 4, -1},
+		{"0 4 5 3 / 1 8 2 7 / 9 10 6 11 12", 3, 4, 12},
+		{"1 0 / 2 3 / 4 5 / 6 7 3", 4, 2, 3},
+	}
+	for _, c := range cases {
+		instance, err := parseSuiteLine(c.text, GoalLayout{})
+		if err != nil {
+			t.Fatalf("%q: %v", c.text, err)
+		}
+		if p := MustNewPuzzle(instance.Goal, instance.Tiles); p.Rows() != c.rows || p.Cols() != c.cols || instance.Optimal != c.optimal {
+			t.Errorf("%q: %vx%v with optimal %v, want %vx%v with %v", c.text, p.Rows(), p.Cols(), instance.Optimal, c.rows, c.cols, c.optimal)
+		}
+	}
+
+	for _, text := range []string{"0 2 3 1 / 4", "0 1 / 2 3 / 4 5 x", "0 / 1 / 2"} {
+		if _, err := parseSuiteLine(text, GoalLayout{}); err == nil {
+			t.Errorf("%q: no error", text)
+		}
+	}
+}
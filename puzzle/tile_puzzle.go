@@ -0,0 +1,627 @@
+/**
+ * Package puzzle is the sliding tile puzzle as a library: boards of any shape with configurable goal
+ * layouts, heuristics, pattern databases, puzzle generators, benchmark suites and IDA*. Puzzles
+ * implement search.State so they can be solved with the generic A* from the search package
+ **/
+package puzzle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"tile-puzzle-ai/search"
+	"unicode"
+)
+
+/**
+ * Struct defines the state of a puzzle
+ * tiles are stored flat in row major order, one byte per tile, so copying a puzzle is a single allocation
+ * tracking zero_loc allows moves to be found in constant time rather than n
+ * tracking solved can be much simpler by checking if the last move puts both tiles in correct position
+ * the goal holds the shape of the board and is shared by every puzzle made from this one
+ **/
+type Puzzle struct {
+	tiles     []byte
+	goal      *Goal
+	zero_loc  RowCol
+	last_move Move
+}
+
+/**
+ * Returns if the states of the two puzzles are equal. Does not care about metadata
+ * like zero location or last move
+ **/
+func (p1 Puzzle) Equals(p2 Puzzle) bool {
+	return p1.Rows() == p2.Rows() && p1.Cols() == p2.Cols() && bytes.Equal(p1.tiles, p2.tiles)
+}
+
+/**
+ * Hashable key identifying the state of a puzzle. Puzzles of up to 16 tiles use the 8 bytes
+ * of their packed form, larger ones use one byte per tile in row major order.
+ * Two puzzles of the same size have the same key exactly when Equals returns true
+ **/
+func (p Puzzle) Key() search.StateKey {
+	if p.Size() <= 16 {
+		var b [8]byte
+		binary.LittleEndian.PutUint64(b[:], p.pack())
+		return search.StateKey(b[:])
+	}
+	return search.StateKey(p.tiles)
+}
+
+/**
+ * Packs a puzzle of up to 16 tiles into a uint64, 4 bits per tile with tile 0 in the lowest bits
+ **/
+func (p Puzzle) pack() uint64 {
+	if p.Size() > 16 {
+		panic("only puzzles of up to 16 tiles can be packed")
+	}
+
+	var packed uint64 = 0
+	for i := p.Size() - 1; i >= 0; i-- {
+		packed = packed<<4 | uint64(p.tiles[i])
+	}
+	return packed
+}
+
+/**
+ * Inverse of pack for a puzzle with the given goal
+ **/
+func unpackPuzzle(packed uint64, goal *Goal) Puzzle {
+	var arr = make([]int, len(goal.tiles))
+	for i := range arr {
+		arr[i] = int(packed & 0xF)
+		packed >>= 4
+	}
+	return newPuzzle(goal, arr)
+}
+
+/**
+ * Bytes of the tiles, which live outside the Puzzle value. Used by the search's memory limit
+ **/
+func (p Puzzle) HeapBytes() int {
+	return cap(p.tiles)
+}
+
+func (p Puzzle) Copy() Puzzle {
+	tiles_copy := make([]byte, len(p.tiles))
+	copy(tiles_copy, p.tiles)
+
+	return Puzzle{
+		tiles:     tiles_copy,
+		goal:      p.goal,
+		zero_loc:  p.zero_loc,
+		last_move: p.last_move,
+	}
+}
+
+/**
+ * function called during creation of puzzle
+ **/
+func (p *Puzzle) set(rc RowCol, val int) {
+	if val == 0 { // track zero_loc
+		p.zero_loc = rc
+	}
+	p.tiles[rc.toN(p.Cols())] = byte(val)
+}
+
+/**
+ * Returns why arr can't be made into a puzzle with the given number of rows and columns, or nil if it can.
+ * Puzzles need at least 2 rows and columns, as on a single line the tiles can't pass each other
+ **/
+func CheckPuzzleArr(rows int, cols int, arr []int) error {
+	if rows < 2 || cols < 2 {
+		return fmt.Errorf("puzzle can't be %vx%v, it needs at least 2 rows and columns", rows, cols)
+	}
+
+	if len(arr) != rows*cols {
+		return fmt.Errorf("puzzle has %v tiles, a %vx%v puzzle needs %v", len(arr), rows, cols, rows*cols)
+	}
+
+	if len(arr) > 256 {
+		return fmt.Errorf("puzzle has %v tiles, at most 256 are supported", len(arr))
+	}
+
+	return checkAllIndices(arr)
+}
+
+/**
+ * Parses a board written as rows separated by / with tiles separated by spaces or commas,
+ * like "1 2 3 / 4 0 5 / 6 7 8", returning the tiles and the shape of the board
+ **/
+func ParsePuzzleStr(str string) (arr []int, rows int, cols int, err error) {
+	var lines []string = strings.Split(str, "/")
+	for r, line := range lines {
+		var fields []string = strings.FieldsFunc(line, func(c rune) bool {
+			return c == ',' || unicode.IsSpace(c)
+		})
+
+		if r == 0 {
+			cols = len(fields)
+		} else if len(fields) != cols {
+			return nil, 0, 0, fmt.Errorf("row %v has %v tiles, expected %v like the first row", r+1, len(fields), cols)
+		}
+
+		for _, field := range fields {
+			tile, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, 0, 0, fmt.Errorf("invalid tile %q in row %v", field, r+1)
+			}
+			arr = append(arr, tile)
+		}
+	}
+	return arr, len(lines), cols, nil
+}
+
+/**
+ * Returns the puzzle with tiles arr in row major order, or an error if they aren't a board of the goal's shape
+ **/
+func NewPuzzle(goal *Goal, arr []int) (Puzzle, error) {
+	if err := CheckPuzzleArr(goal.height, goal.width, arr); err != nil {
+		return Puzzle{}, err
+	}
+	return newPuzzle(goal, arr), nil
+}
+
+/**
+ * Like NewPuzzle but panics if the tiles are invalid, for boards that have already been checked
+ **/
+func MustNewPuzzle(goal *Goal, arr []int) Puzzle {
+	p, err := NewPuzzle(goal, arr)
+	if err != nil {
+		panic("Invalid input arr for puzzle: " + err.Error())
+	}
+	return p
+}
+
+/**
+ * Builds the puzzle without checking the tiles, which must be valid
+ **/
+func newPuzzle(goal *Goal, arr []int) Puzzle {
+	// create puzzle array
+	var p = Puzzle{
+		tiles:     make([]byte, len(arr)),
+		goal:      goal,
+		zero_loc:  RowCol{0, 0},
+		last_move: None,
+	}
+
+	// init puzzle array
+	for i, e := range arr {
+		p.set(p.nToCoord(i), e)
+	}
+
+	return p
+}
+
+func NewPuzzleSolved(goal *Goal) Puzzle {
+	var arr = make([]int, len(goal.tiles))
+	for i, e := range goal.tiles {
+		arr[i] = int(e)
+	}
+	return newPuzzle(goal, arr)
+}
+
+/**
+ * Returns the goal after swaps random moves drawn from r
+ **/
+func NewPuzzleSwapped(goal *Goal, swaps int, r *rand.Rand) Puzzle {
+	var p Puzzle = NewPuzzleSolved(goal)
+
+	// make n random moves on the board
+	for i := 0; i < swaps; i++ {
+		moves := p.getNewMoves()
+		p.makeMove(moves[r.Intn(len(moves))])
+	}
+
+	p.last_move = None
+
+	return p
+}
+
+/**
+ * Makes random moves from the goal until misplaced tiles are out of place, returning the puzzle
+ * and the number of moves made. The moves are drawn from r
+ **/
+func NewPuzzleMisplaced(goal *Goal, misplaced int, r *rand.Rand) (Puzzle, int) {
+	if misplaced > len(goal.tiles)-1 {
+		misplaced = len(goal.tiles) - 1
+	} else if misplaced < 0 {
+		misplaced = 0
+	}
+
+	var p Puzzle = NewPuzzleSolved(goal)
+
+	// make n random moves on the board
+	var swaps int = 0
+	for int(MisplacedTiles(p)) < misplaced {
+		moves := p.getNewMoves()
+		p.makeMove(moves[r.Intn(len(moves))])
+		swaps++
+	}
+
+	p.last_move = None
+
+	return p, swaps
+}
+
+/**
+ * Returns a puzzle drawn uniformly from every solvable state. The tiles are shuffled and if the result
+ * can't be solved two tiles (not the blank) are swapped, which pairs every unsolvable state with one solvable one
+ **/
+func NewPuzzleUniform(goal *Goal, r *rand.Rand) Puzzle {
+	var p Puzzle = newPuzzle(goal, r.Perm(len(goal.tiles)))
+
+	if !p.IsSolvable() && p.Size() > 2 {
+		var first, second int = 0, 1
+		if p.GetN(first) == 0 {
+			first = 2
+		} else if p.GetN(second) == 0 {
+			second = 2
+		}
+		p.swap(p.nToCoord(first), p.nToCoord(second))
+	}
+
+	return p
+}
+
+func (p Puzzle) nToRow(n int) int {
+	return n / p.goal.width
+}
+
+func (p Puzzle) nToCol(n int) int {
+	return n % p.goal.width
+}
+
+func (p Puzzle) nToCoord(n int) RowCol {
+	return RowCol{
+		row: p.nToRow(n),
+		col: p.nToCol(n),
+	}
+}
+
+func (p Puzzle) Rows() int {
+	return p.goal.height
+}
+
+func (p Puzzle) Cols() int {
+	return p.goal.width
+}
+
+/**
+ * Size as written in the log, the side length of a square puzzle or rows x cols
+ **/
+func (p Puzzle) ShapeStr() string {
+	if p.Rows() == p.Cols() {
+		return fmt.Sprint(p.Cols())
+	}
+	return fmt.Sprintf("%vx%v", p.Rows(), p.Cols())
+}
+
+/**
+ * Goal the puzzle is solved towards, shared with every puzzle made from this one
+ **/
+func (p Puzzle) Goal() *Goal {
+	return p.goal
+}
+
+func (p Puzzle) Size() int {
+	return len(p.tiles)
+}
+
+func (p Puzzle) get(rc RowCol) int {
+	return int(p.tiles[rc.toN(p.Cols())])
+}
+
+func (p Puzzle) GetN(n int) int {
+	return int(p.tiles[n])
+}
+
+func (p Puzzle) getGoalPos(val int) RowCol {
+	return p.goal.pos[val]
+}
+
+/**
+ * Tile at index n of the goal state
+ **/
+func (p Puzzle) getGoalN(n int) int {
+	return int(p.goal.tiles[n])
+}
+
+/**
+ * Prints out the state of the puzzle
+ * only works up to 2 digit numbers
+ * looks weird with 100 or more tiles, which is infeasible anyways
+ */
+func (p Puzzle) Print() {
+	fmt.Print(p.ToStr())
+}
+
+func (p Puzzle) ToStr() string {
+	var sb strings.Builder
+	for i := 0; i < p.Rows(); i++ {
+		sb.WriteString(strings.Repeat("+----", p.Cols()) + "+\n")
+		for j := 0; j < p.Cols(); j++ {
+			sb.WriteString("| ")
+
+			e := p.get(RowCol{
+				row: i,
+				col: j,
+			})
+
+			if e == 0 {
+				sb.WriteString("  ")
+			} else {
+				if e < 10 {
+					sb.WriteString(" ")
+				}
+				sb.WriteString(fmt.Sprint(e))
+			}
+
+			sb.WriteString(" ")
+		}
+		sb.WriteString("|\n")
+	}
+
+	sb.WriteString(strings.Repeat("+----", p.Cols()) + "+\n")
+	return sb.String()
+}
+
+func (p Puzzle) IsSolved() bool {
+	return bytes.Equal(p.tiles, p.goal.tiles)
+}
+
+/**
+ * Returns if the goal can be reached from this state
+ * Every move swaps the blank with a tile, which flips the parity of the permutation of the tiles,
+ * and moves the blank one cell, which flips the parity of its distance from its goal cell. So the two
+ * parities have to match. For the blank-top-left goal this is the inversion count, plus the row of the blank
+ * on boards with an even width. On boards with at least 2 rows and columns, rectangular ones included, every
+ * state with matching parities can reach the goal, whatever its layout. On a single row or column the tiles
+ * can't pass each other so parity isn't enough, which is why CheckPuzzleArr rejects them
+ **/
+func (p Puzzle) IsSolvable() bool {
+	var goalIdx []int = make([]int, p.Size()) // goal index of the tile at each index
+	for i := range goalIdx {
+		goalIdx[i] = p.getGoalPos(p.GetN(i)).toN(p.Cols())
+	}
+
+	// a permutation of n elements with c cycles is made of n - c swaps
+	var visited []bool = make([]bool, p.Size())
+	var cycles int = 0
+	for i := range goalIdx {
+		if visited[i] {
+			continue
+		}
+		cycles++
+		for j := i; !visited[j]; j = goalIdx[j] {
+			visited[j] = true
+		}
+	}
+	var permParity int = (p.Size() - cycles) % 2
+
+	var blankGoal RowCol = p.getGoalPos(0)
+	var blankDist int = abs(p.zero_loc.row-blankGoal.row) + abs(p.zero_loc.col-blankGoal.col)
+
+	return permParity == blankDist%2
+}
+
+/**
+ * Returns the cells next to rc that are on the board
+ **/
+func (p Puzzle) neighbours(rc RowCol) []RowCol {
+	var cells []RowCol = make([]RowCol, 0, 4)
+	if rc.row > 0 {
+		cells = append(cells, RowCol{row: rc.row - 1, col: rc.col})
+	}
+	if rc.row < p.Rows()-1 {
+		cells = append(cells, RowCol{row: rc.row + 1, col: rc.col})
+	}
+	if rc.col > 0 {
+		cells = append(cells, RowCol{row: rc.row, col: rc.col - 1})
+	}
+	if rc.col < p.Cols()-1 {
+		cells = append(cells, RowCol{row: rc.row, col: rc.col + 1})
+	}
+	return cells
+}
+
+func (p Puzzle) getMoves() []Move {
+	var moves []Move
+
+	if p.zero_loc.row > 0 {
+		moves = append(moves, Down)
+	}
+
+	if p.zero_loc.row < p.Rows()-1 {
+		moves = append(moves, Up)
+	}
+
+	if p.zero_loc.col > 0 {
+		moves = append(moves, Right)
+	}
+
+	if p.zero_loc.col < p.Cols()-1 {
+		moves = append(moves, Left)
+	}
+
+	return moves
+}
+
+func (p Puzzle) getNewMoves() []Move {
+	var moves []Move
+
+	if p.zero_loc.row > 0 && p.last_move != Up {
+		moves = append(moves, Down)
+	}
+
+	if p.zero_loc.row < p.Rows()-1 && p.last_move != Down {
+		moves = append(moves, Up)
+	}
+
+	if p.zero_loc.col > 0 && p.last_move != Left {
+		moves = append(moves, Right)
+	}
+
+	if p.zero_loc.col < p.Cols()-1 && p.last_move != Right {
+		moves = append(moves, Left)
+	}
+
+	return moves
+}
+
+/*
+ * Returns the successors to a puzzle
+ * ignore_prev_moves doesn't include the opposite of the last move played,
+ * as it's already been explored. false will include them.
+ */
+func (p Puzzle) getSuccessors(ignore_prev_moves bool) []Puzzle {
+	var successors []Puzzle = []Puzzle{}
+
+	var moves []Move
+	if ignore_prev_moves {
+		moves = p.getNewMoves()
+	} else {
+		moves = p.getMoves()
+	}
+
+	for _, move := range moves {
+		successors = append(successors, p.tryMove(move))
+	}
+
+	return successors
+}
+
+/**
+ * State implementation for A*, every move costs 1
+ **/
+var _ search.State[Puzzle] = Puzzle{}
+
+func (p Puzzle) Successors(skip_reverse bool) []search.Successor[Puzzle] {
+	var states []Puzzle = p.getSuccessors(skip_reverse)
+	var successors []search.Successor[Puzzle] = make([]search.Successor[Puzzle], len(states))
+	for i, state := range states {
+		successors[i] = search.Successor[Puzzle]{State: state, Cost: 1}
+	}
+	return successors
+}
+
+func (p Puzzle) IsGoal() bool {
+	return p.IsSolved()
+}
+
+func (p *Puzzle) swap(rc1 RowCol, rc2 RowCol) {
+	n1, n2 := rc1.toN(p.Cols()), rc2.toN(p.Cols())
+	p.tiles[n1], p.tiles[n2] = p.tiles[n2], p.tiles[n1]
+	if p.tiles[n1] == 0 {
+		p.zero_loc = rc1
+	} else if p.tiles[n2] == 0 {
+		p.zero_loc = rc2
+	}
+}
+
+/**
+ * makes a move on the puzzle
+ **/
+func (p *Puzzle) makeMove(m Move) {
+	p.last_move = m
+	switch m {
+	case Up:
+		p.swap(p.zero_loc, RowCol{
+			row: p.zero_loc.row + 1,
+			col: p.zero_loc.col,
+		})
+
+	case Down:
+		p.swap(p.zero_loc, RowCol{
+			row: p.zero_loc.row - 1,
+			col: p.zero_loc.col,
+		})
+
+	case Left:
+		p.swap(p.zero_loc, RowCol{
+			row: p.zero_loc.row,
+			col: p.zero_loc.col + 1,
+		})
+
+	case Right:
+		p.swap(p.zero_loc, RowCol{
+			row: p.zero_loc.row,
+			col: p.zero_loc.col - 1,
+		})
+	}
+}
+
+/**
+ * Returns a copy of the puzzle with the moves made in order, or an error at the first move
+ * that would slide a tile off the board
+ **/
+func (p Puzzle) ApplyMoves(moves []Move) (Puzzle, error) {
+	p = p.Copy()
+	for i, m := range moves {
+		var legal bool = false
+		for _, possible := range p.getMoves() {
+			legal = legal || possible == m
+		}
+		if !legal {
+			return p, fmt.Errorf("move %v (%v) has no tile to slide", i+1, m)
+		}
+		p.makeMove(m)
+	}
+	return p, nil
+}
+
+/**
+ * Returns the moves made along a path from solve, which is stored goal first.
+ * Each move is found from where the blank went, as A* can give a state a cheaper parent
+ * than the one it was made from, leaving its last move out of date
+ **/
+func PathMoves(path []Puzzle) []Move {
+	var moves []Move
+	for i := len(path) - 2; i >= 0; i-- {
+		moves = append(moves, blankMove(path[i+1].zero_loc, path[i].zero_loc))
+	}
+	return moves
+}
+
+/**
+ * Returns the move that takes the blank from one cell to the one next to it
+ **/
+func blankMove(from RowCol, to RowCol) Move {
+	switch {
+	case to.row == from.row+1:
+		return Up
+	case to.row == from.row-1:
+		return Down
+	case to.col == from.col+1:
+		return Left
+	case to.col == from.col-1:
+		return Right
+	default:
+		return None
+	}
+}
+
+/**
+ * returns a copy of the puzzle with the move made
+ **/
+func (p Puzzle) tryMove(m Move) Puzzle {
+	p = p.Copy()
+	p.makeMove(m)
+	return p
+}
+
+/**
+ * returns if p is a successor to prev, meaning it takes 1 move to go between p and prev
+ **/
+func (p Puzzle) isSuccessorTo(prev Puzzle) bool {
+	for _, m := range prev.getMoves() {
+		if prev.tryMove(m).Equals(p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package puzzle
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"tile-puzzle-ai/search"
+)
+
+func TestPathMovesSolveBoard(t *testing.T) {
+	var cases = []struct {
+		rows, cols int
+		goal       string
+		h          Heuristic
+		seed       int64
+	}{
+		{3, 3, BlankTopLeft, ManhattanDistance, 1},
+		{2, 4, BlankBottomRight, WalkingDistance, 2},
+		{3, 4, BlankBottomRight, WalkingDistanceLinearConflict, 3},
+	}
+
+	// A* gives this board's path states with last moves from parents it later replaced
+	goal, _ := NewGoalPreset(3, 3, BlankTopLeft)
+	var boards = []Puzzle{MustNewPuzzle(goal, []int{8, 6, 7, 2, 5, 4, 3, 0, 1})}
+	var heuristics = []Heuristic{ManhattanDistance}
+	for _, c := range cases {
+		goal, err := NewGoalPreset(c.rows, c.cols, c.goal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := rand.New(rand.NewSource(c.seed))
+		for i := 0; i < 5; i++ {
+			boards = append(boards, NewPuzzleUniform(goal, r))
+			heuristics = append(heuristics, c.h)
+		}
+	}
+
+	for i, p := range boards {
+		result := Solve(context.Background(), p, AStar, heuristics[i], search.Limits{}, true)
+		if result.Status != search.Solved {
+			t.Fatalf("%v: status %v", p.ToStr(), result.Status)
+		}
+		moves := PathMoves(result.Path)
+		if len(moves) != len(result.Path)-1 {
+			t.Fatalf("%v: %v moves for a path of %v states", p.ToStr(), len(moves), len(result.Path))
+		}
+		end, err := p.ApplyMoves(moves)
+		if err != nil {
+			t.Fatalf("%v: %v", p.ToStr(), err)
+		}
+		if !end.IsSolved() {
+			t.Fatalf("%v: moves %v don't solve the board", p.ToStr(), moves)
+		}
+	}
+}
+
+func TestNewPuzzleInvalid(t *testing.T) {
+	goal, _ := NewGoalPreset(3, 3, BlankTopLeft)
+	for _, arr := range [][]int{{0, 1, 2, 3}, {0, 1, 2, 3, 4, 5, 6, 7, 7}, {0, 1, 2, 3, 4, 5, 6, 7, 9}} {
+		if _, err := NewPuzzle(goal, arr); err == nil {
+			t.Errorf("%v: no error", arr)
+		}
+	}
+	if p, err := NewPuzzle(goal, []int{1, 0, 2, 3, 4, 5, 6, 7, 8}); err != nil || p.ToStr() != MustNewPuzzle(goal, []int{1, 0, 2, 3, 4, 5, 6, 7, 8}).ToStr() {
+		t.Errorf("valid board: %v", err)
+	}
+
+	for _, num := range []int{0, PDBHeuristic, NumHeuristics + 1} {
+		if h, err := GetHeuristic(num, nil); err == nil || h != nil {
+			t.Errorf("heuristic %v without a pattern database: no error", num)
+		}
+	}
+}
+
+func TestPackKey(t *testing.T) {
+	goal, _ := NewGoalPreset(2, 2, BlankBottomRight)
+	if packed := MustNewPuzzle(goal, []int{1, 2, 3, 0}).pack(); packed != 0x0321 {
+		t.Errorf("packed %#x, want 0x321 with the first tile lowest", packed)
+	}
+
+	var cases = []struct{ rows, cols int }{{2, 2}, {3, 3}, {4, 4}, {2, 8}, {5, 5}}
+	for _, c := range cases {
+		goal, err := NewGoalPreset(c.rows, c.cols, BlankTopLeft)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := rand.New(rand.NewSource(1))
+		var keys = map[search.StateKey]string{}
+		for i := 0; i < 200; i++ {
+			p := NewPuzzleUniform(goal, r)
+			if p.Size() <= 16 {
+				if q := unpackPuzzle(p.pack(), goal); q.ToStr() != p.ToStr() || q.zero_loc != p.zero_loc {
+					t.Errorf("%vx%v: unpacked %v, want %v", c.rows, c.cols, q.ToStr(), p.ToStr())
+				}
+			}
+
+			// the key only depends on the tiles, not the move that reached them
+			moved := p.Copy()
+			moved.last_move = Up
+			if p.Key() != moved.Key() {
+				t.Errorf("%vx%v: key depends on the last move", c.rows, c.cols)
+			}
+			if other, ok := keys[p.Key()]; ok && other != p.ToStr() {
+				t.Errorf("%vx%v: %v and %v have the same key", c.rows, c.cols, other, p.ToStr())
+			}
+			keys[p.Key()] = p.ToStr()
+		}
+	}
+}
@@ -1,7 +1,9 @@
-package main
+package puzzle
 
 import (
+	"fmt"
 	"math"
+	"strings"
 )
 
 /**
@@ -47,40 +49,87 @@ func (m Move) opposite() Move {
 }
 
 /**
- * Returns the index of the 2d array as if it was a single array
+ * Letter for a move, named by the direction the tile next to the blank slides
  **/
-func (rc RowCol) toN(len int) int {
-	return len*rc.row + rc.col
+func (m Move) String() string {
+	switch m {
+	case Down:
+		return "D"
+	case Left:
+		return "L"
+	case Right:
+		return "R"
+	case Up:
+		return "U"
+	default:
+		return "-"
+	}
+}
+
+/**
+ * Parses moves written as letters like "ULDR", ignoring spaces and case
+ **/
+func ParseMoves(str string) ([]Move, error) {
+	var moves []Move
+	for _, c := range strings.ToUpper(str) {
+		switch c {
+		case 'D':
+			moves = append(moves, Down)
+		case 'L':
+			moves = append(moves, Left)
+		case 'R':
+			moves = append(moves, Right)
+		case 'U':
+			moves = append(moves, Up)
+		case ' ', ',':
+		default:
+			return nil, fmt.Errorf("invalid move %q, moves are U, D, L and R", c)
+		}
+	}
+	return moves, nil
+}
+
+/**
+ * Returns the index of the 2d array as if it was a single array, cols is the width of the array
+ **/
+func (rc RowCol) toN(cols int) int {
+	return cols*rc.row + rc.col
 }
 
 /**
  * input validation to ensure a puzzle only has unique digits from 0 to n-1
- * assumes isSquare has been called so does not check for a square number range
  **/
-func containsAllIndices(arr []int) bool {
+func checkAllIndices(arr []int) error {
 	var seen []bool = make([]bool, len(arr))
 	for _, e := range arr {
 		if e < 0 || e >= len(arr) {
-			return false
+			return fmt.Errorf("tile %v is out of range, tiles must be 0 to %v", e, len(arr)-1)
 		}
 
 		if seen[e] {
-			return false
+			return fmt.Errorf("tile %v appears more than once", e)
 		} else {
 			seen[e] = true
 		}
 	}
-	return true
+	return nil
 }
 
 /*
  * return if the number is square and what it's integer base is
  */
-func isSquare(n int) (bool, int) {
+func IsSquare(n int) (bool, int) {
 	base, rem := math.Modf(math.Sqrt(float64(n)))
 	return rem == 0, int(base)
 }
 
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
 /**
  * remove an element from a slice (doesn't retain ordering)
  */
@@ -104,28 +153,6 @@ func remove(s []int, i int) []int {
 	return s[:len(s)-1]
 }
 
-var euclid_lookup = make(map[RowCol]float32, 9)
-
 func euclidean_dist(x int, y int) float32 {
-	if x < 0 {
-		x = -x
-	}
-
-	if y < 0 {
-		y = -y
-	}
-
-	if x > y {
-		temp := x
-		x = y
-		y = temp
-	}
-
-	if val, ok := euclid_lookup[RowCol{x, y}]; ok {
-		return val
-	} else {
-		var dist float32 = float32(math.Sqrt(math.Pow(float64(x), 2) + math.Pow(float64(y), 2)))
-		euclid_lookup[RowCol{x, y}] = dist
-		return dist
-	}
+	return float32(math.Sqrt(math.Pow(float64(x), 2) + math.Pow(float64(y), 2)))
 }
@@ -0,0 +1,247 @@
+package puzzle
+
+import "sync"
+
+/**
+ * Walking distance heuristic by Ken'ichiro Takahashi
+ * Vertical moves only change which row tiles are in, so the state of the rows can be reduced to
+ * how many tiles in each row belong to each goal row, plus the row of the blank. A breadth first
+ * search from the goal over these reduced states gives the fewest vertical moves needed, and the
+ * same with columns gives the fewest horizontal moves. Their sum is admissible and never less than
+ * manhattan distance, as it also counts the moves needed for tiles in the same line to pass each other.
+ **/
+
+/**
+ * Distance to the goal of every reduced state, keyed by the counts (row major, line then goal line)
+ * followed by the line of the blank
+ **/
+type wdTable map[string]uint8
+
+type walkingDistance struct {
+	rows wdTable
+	cols wdTable
+}
+
+/**
+ * Tables of one goal, built once by whichever search needs them first
+ **/
+type wdEntry struct {
+	once sync.Once
+	wd   *walkingDistance
+}
+
+var wd_cache sync.Map // *wdEntry for each goal, by goal id, read by every search
+
+const wd_max_states = 1 << 20 // larger tables aren't built and that direction falls back to manhattan distance
+
+/**
+ * Returns the tables for a goal, building them the first time they are needed. Only searches
+ * with the same goal wait for the build. A 4x4 board has about 25000 states in each table, but
+ * long lines like the columns of a 2x8 board have too many and are left nil without searching them
+ **/
+func getWalkingDistance(goal *Goal) *walkingDistance {
+	entry, _ := wd_cache.LoadOrStore(goal.id, &wdEntry{})
+	var e *wdEntry = entry.(*wdEntry)
+	e.once.Do(func() {
+		rows, blankRow, cols, blankCol := wdCounts(NewPuzzleSolved(goal))
+		e.wd = &walkingDistance{rows: buildWDTable(goal.height, rows, blankRow)}
+		if string(rows) == string(cols) && blankRow == blankCol { // goal is symmetric, share the table
+			e.wd.cols = e.wd.rows
+		} else {
+			e.wd.cols = buildWDTable(goal.width, cols, blankCol)
+		}
+	})
+	return e.wd
+}
+
+/**
+ * Builds the walking distance tables for a goal if they haven't been built yet, so it can be done
+ * before a search starts instead of inside its first heuristic call
+ **/
+func PrepareWalkingDistance(goal *Goal) {
+	getWalkingDistance(goal)
+}
+
+/**
+ * Counts how many tiles in each row belong in each goal row, and the same for columns,
+ * along with the row and column of the blank
+ **/
+func wdCounts(p Puzzle) (rows []byte, blankRow int, cols []byte, blankCol int) {
+	rows = make([]byte, p.Rows()*p.Rows())
+	cols = make([]byte, p.Cols()*p.Cols())
+	for i := 0; i < p.Size(); i++ {
+		e := p.GetN(i)
+		pos := p.nToCoord(i)
+		if e == 0 {
+			blankRow, blankCol = pos.row, pos.col
+			continue
+		}
+		goalPos := p.getGoalPos(e)
+		rows[pos.row*p.Rows()+goalPos.row]++
+		cols[pos.col*p.Cols()+goalPos.col]++
+	}
+	return rows, blankRow, cols, blankCol
+}
+
+func wdKey(counts []byte, blank int) string {
+	var key []byte = make([]byte, len(counts)+1)
+	copy(key, counts)
+	key[len(counts)] = byte(blank)
+	return string(key)
+}
+
+/**
+ * Breadth first search over reduced states starting from the goal, n is the number of lines.
+ * A move swaps the blank with a tile of any goal line from the line above or below it.
+ * Returns nil without searching if there are more than wd_max_states states
+ **/
+func buildWDTable(n int, goalCounts []byte, goalBlank int) wdTable {
+	if wdStateCount(n, goalCounts, goalBlank, wd_max_states) > wd_max_states {
+		return nil
+	}
+
+	var start string = wdKey(goalCounts, goalBlank)
+	var table = wdTable{start: 0}
+	var queue []string = []string{start}
+
+	var counts []byte = make([]byte, n*n)
+	for head := 0; head < len(queue); head++ {
+		var key string = queue[head]
+		var dist uint8 = table[key]
+		copy(counts, key[:n*n])
+		var blank int = int(key[n*n])
+
+		for _, line := range [2]int{blank - 1, blank + 1} {
+			if line < 0 || line >= n {
+				continue
+			}
+			for g := 0; g < n; g++ {
+				if counts[line*n+g] == 0 {
+					continue
+				}
+				counts[line*n+g]--
+				counts[blank*n+g]++
+				if next := wdKey(counts, line); !wdSeen(table, next) {
+					table[next] = dist + 1
+					queue = append(queue, next)
+				}
+				counts[line*n+g]++
+				counts[blank*n+g]--
+			}
+		}
+	}
+
+	return table
+}
+
+/**
+ * Number of reduced states of a table, or limit + 1 if there are more than limit. For each line of the
+ * blank, counts the ways to share the tiles of each line between the goal lines so every goal line gets
+ * all of its tiles, going line by line and remembering the ways to finish from each set of goal line
+ * tiles left. Every one of these states can be reached, so this is the size of the table
+ **/
+func wdStateCount(n int, goalCounts []byte, goalBlank int, limit int) int {
+	var lineTiles []int = make([]int, n) // tiles in each line with the blank in its goal line
+	var left []byte = make([]byte, n)    // tiles of each goal line
+	for line := 0; line < n; line++ {
+		for g := 0; g < n; g++ {
+			lineTiles[line] += int(goalCounts[line*n+g])
+			left[g] += goalCounts[line*n+g]
+		}
+	}
+	lineTiles[goalBlank]++
+
+	var total int = 0
+	for blank := 0; blank < n; blank++ {
+		var memo = map[string]int{}
+		var ways func(line int, left []byte) int
+		ways = func(line int, left []byte) int {
+			if line == n {
+				return 1 // every tile was placed, as the line and goal line totals match
+			}
+			var key string = string(rune(line)) + string(left)
+			if count, ok := memo[key]; ok {
+				return count
+			}
+			if len(memo) > limit { // too many to count, so too many to build
+				return limit + 1
+			}
+
+			var tiles int = lineTiles[line]
+			if line == blank {
+				tiles--
+			}
+			var count int = 0
+			var next []byte = make([]byte, n)
+			var share func(g int, tiles int)
+			share = func(g int, tiles int) {
+				if count > limit {
+					return
+				}
+				if g == n {
+					if tiles == 0 {
+						count += ways(line+1, next)
+					}
+					return
+				}
+				for k := 0; k <= tiles && k <= int(left[g]); k++ {
+					next[g] = left[g] - byte(k)
+					share(g+1, tiles-k)
+				}
+			}
+			share(0, tiles)
+			if count > limit {
+				count = limit + 1
+			}
+			memo[key] = count
+			return count
+		}
+
+		if total += ways(0, left); total > limit {
+			return limit + 1
+		}
+	}
+	return total
+}
+
+func wdSeen(table wdTable, key string) bool {
+	_, ok := table[key]
+	return ok
+}
+
+/**
+ * Walking distance, vertical moves from the row table plus horizontal moves from the column table
+ **/
+func WalkingDistance(p Puzzle) float32 {
+	var wd *walkingDistance = getWalkingDistance(p.goal)
+	rows, blankRow, cols, blankCol := wdCounts(p)
+	return wdLookup(wd.rows, p.Rows(), rows, blankRow) + wdLookup(wd.cols, p.Cols(), cols, blankCol)
+}
+
+/**
+ * Distance of a reduced state, or the manhattan distance along that direction if the table wasn't built
+ **/
+func wdLookup(table wdTable, n int, counts []byte, blank int) float32 {
+	if table != nil {
+		return float32(table[wdKey(counts, blank)])
+	}
+
+	var sum int = 0
+	for line := 0; line < n; line++ {
+		for g := 0; g < n; g++ {
+			sum += int(counts[line*n+g]) * abs(line-g)
+		}
+	}
+	return float32(sum)
+}
+
+/**
+ * Walking distance or linear conflict, whichever is larger. Both are admissible so the max is too
+ **/
+func WalkingDistanceLinearConflict(p Puzzle) float32 {
+	var wd, lc float32 = WalkingDistance(p), LinearConflict(p)
+	if wd > lc {
+		return wd
+	}
+	return lc
+}
@@ -0,0 +1,35 @@
+package puzzle
+
+import "testing"
+
+func TestWDStateCount(t *testing.T) {
+	for _, shape := range [][2]int{{2, 2}, {3, 3}, {4, 4}, {3, 4}, {2, 5}, {2, 6}} {
+		for _, preset := range GoalPresets {
+			goal, err := NewGoalPreset(shape[0], shape[1], preset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rows, blankRow, cols, blankCol := wdCounts(NewPuzzleSolved(goal))
+			for _, dir := range []struct {
+				n      int
+				counts []byte
+				blank  int
+			}{{shape[0], rows, blankRow}, {shape[1], cols, blankCol}} {
+				var table wdTable = buildWDTable(dir.n, dir.counts, dir.blank)
+				if count := wdStateCount(dir.n, dir.counts, dir.blank, wd_max_states); count != len(table) {
+					t.Errorf("%vx%v %v, %v lines: counted %v states, the table has %v", shape[0], shape[1], preset, dir.n, count, len(table))
+				}
+			}
+		}
+	}
+
+	// the columns of a 2x8 board have about a billion states, so they're counted as too many without building them
+	goal, _ := NewGoalPreset(2, 8, BlankTopLeft)
+	_, _, cols, blankCol := wdCounts(NewPuzzleSolved(goal))
+	if count := wdStateCount(8, cols, blankCol, wd_max_states); count <= wd_max_states {
+		t.Errorf("2x8 columns: counted %v states", count)
+	}
+	if table := buildWDTable(8, cols, blankCol); table != nil {
+		t.Errorf("2x8 columns: built a table of %v states", len(table))
+	}
+}
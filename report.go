@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"log"
+	"tile-puzzle-ai/puzzle"
+	"tile-puzzle-ai/search"
+	"time"
+)
+
+/**
+ * Solves the puzzle of a run and logs the metrics enabled in the config to the run's log.
+ * The run's optimal is the known optimal solution length, or -1 if it isn't known.
+ * Progress reports go to the status line and progress file while it searches
+ **/
+func solve(ctx context.Context, r *run) {
+	if r.time_limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(r.time_limit*float64(time.Second)))
+		defer cancel()
+	}
+
+	if config.Progress.Interval > 0 && (status != nil || progress_file != nil) {
+		ctx = search.WithProgress(ctx, time.Duration(config.Progress.Interval*float64(time.Second)), func(p search.Progress) {
+			reportProgress(r, p)
+		})
+	}
+
+	startSearch(r)
+	r.result = puzzle.Solve(ctx, r.initial, r.algorithm, r.h, r.limits, r.skip_reverse)
+	var shared int = endSearch(r)
+	status.clear()
+
+	var logger *log.Logger = r.logger // the run's own log, written out in order by runConfig
+	var initial, algorithm, optimal, result = r.initial, r.algorithm, r.optimal, r.result
+
+	if result.Status == search.Unsolvable && !initial.IsSolvable() { // found without searching
+		if config.Metrics.Status {
+			logger.Printf("Status: %v\n", result.Status)
+			logger.Printf("Reason: the parity of the tile permutation doesn't match the blank's distance from its goal\n")
+		}
+		return
+	}
+
+	if config.Metrics.Status {
+		logStatus(logger, result.Status, result.Path, optimal)
+	}
+
+	if config.Metrics.Execution_time {
+		logger.Printf("Execution time: %.3fs\n", result.Duration.Seconds())
+		if shared > 0 {
+			logger.Printf("Shared CPU: up to %v searches ran at once\n", shared+1)
+		}
+	}
+
+	if result.Status == search.Solved && config.Metrics.Solution_length {
+		logger.Printf("Solution Length: %v\n", len(result.Path)-1)
+	}
+
+	if result.Status != search.Solved && config.Metrics.Status { // how far it got before stopping
+		logger.Printf("Best f: %v, Deepest g: %v, Nodes Stored: %v\n", result.BestF, result.DeepestG, result.Stored)
+		if result.Bytes > 0 {
+			logger.Printf("Memory Stored: %.1fMB\n", float64(result.Bytes)/(1<<20))
+		}
+	}
+
+	if algorithm == puzzle.IDAStar {
+		if config.Metrics.Iterations {
+			logger.Printf("Iterations: %v\n", len(result.Thresholds))
+			logger.Printf("Thresholds: %v\n", result.Thresholds)
+		}
+
+		if config.Metrics.Nodes_explored {
+			logger.Printf("Nodes Generated: %v\n", result.Generated)
+		}
+	} else {
+		if config.Metrics.Nodes_explored {
+			logger.Printf("Nodes Explored: %v\n", result.Frontier+result.Expanded)
+		}
+
+		if config.Metrics.Frontier_size {
+			logger.Printf("Frontier Size: %v\n", result.Frontier)
+		}
+	}
+
+	if config.Metrics.Nodes_evaluated {
+		logger.Printf("Nodes Evaluated: %v\n", result.Expanded)
+	}
+
+	if result.Status == search.Solved && config.Metrics.Solution_path {
+		logSolutionPath(logger, result.Path)
+	}
+}
+
+/**
+ * prints the status, and for solutions whether the path is valid and optimal when the optimal length is known
+ **/
+func logStatus(logger *log.Logger, status search.Status, path []puzzle.Puzzle, optimal int) {
+	if status != search.Solved {
+		logger.Printf("Status: %v\n", status)
+	} else if optimal < 0 {
+		logger.Printf("Status: %v, Valid: %v\n", status, puzzle.VerifySolution(path))
+	} else {
+		logger.Printf("Status: %v, Valid: %v, Optimal: %v\n", status, puzzle.VerifySolution(path), puzzle.VerifyOptimal(path, optimal))
+	}
+}
+
+/**
+ * prints a path from solve, which is stored goal first, starting from the initial state
+ **/
+func logSolutionPath(logger *log.Logger, path []puzzle.Puzzle) {
+	logger.Printf("Solution Path:\n")
+	for i := len(path) - 1; i >= 0; i-- {
+		logger.Print(path[i].ToStr())
+		logger.Println()
+	}
+	logger.Printf("Solution Moves: %v\n", movesStr(puzzle.PathMoves(path)))
+}
+
+func movesStr(moves []puzzle.Move) string {
+	var str string
+	for _, m := range moves {
+		str += m.String()
+	}
+	return str
+}
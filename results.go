@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"tile-puzzle-ai/search"
+)
+
+/**
+ * Structured results, one record per puzzle and heuristic written as JSON Lines or CSV
+ * so runs can be analysed without scraping the text log
+ **/
+
+const (
+	JSONLResults = "jsonl"
+	CSVResults   = "csv"
+)
+
+func validResultsFormat(format string) bool {
+	return format == "" || format == "none" || format == JSONLResults || format == CSVResults
+}
+
+func writesResults(config Config) bool {
+	return config.Results.Format == JSONLResults || config.Results.Format == CSVResults
+}
+
+type ResultRecord struct {
+	Label     string  `json:"label"` // same as the Puzzle line of the text log
+	Input     int     `json:"input"` // from 1
+	Instance  int     `json:"instance"`
+	Seed      int64   `json:"seed"` // seed the puzzle was generated from, 0 for given boards
+	Rows      int     `json:"rows"`
+	Cols      int     `json:"cols"`
+	Goal      string  `json:"goal"`
+	Initial   string  `json:"initial"`
+	Heuristic int     `json:"heuristic"`
+	Algorithm string  `json:"algorithm"`
+	Status    string  `json:"status"`
+	Time      float64 `json:"time"`   // seconds
+	Length    int     `json:"length"` // solution length, -1 if not solved
+	Optimal   int     `json:"optimal"`
+	Expanded  int     `json:"expanded"`
+	Generated int     `json:"generated"`
+	Frontier  int     `json:"frontier"`
+	BestF     float32 `json:"best_f"`    // highest f expanded
+	DeepestG  float32 `json:"deepest_g"` // highest g expanded
+	Stored    int     `json:"stored"`    // nodes kept in memory when the search stopped
+	Shared    int     `json:"shared"`    // most other searches running alongside, the time is less reliable above 0
+}
+
+var csv_header = []string{"label", "input", "instance", "seed", "rows", "cols", "goal", "initial", "heuristic",
+	"algorithm", "status", "time", "length", "optimal", "expanded", "generated", "frontier", "best_f", "deepest_g", "stored", "shared"}
+
+func (r ResultRecord) csvRow() []string {
+	return []string{r.Label, strconv.Itoa(r.Input), strconv.Itoa(r.Instance), strconv.FormatInt(r.Seed, 10),
+		strconv.Itoa(r.Rows), strconv.Itoa(r.Cols), r.Goal, r.Initial, strconv.Itoa(r.Heuristic), r.Algorithm,
+		r.Status, strconv.FormatFloat(r.Time, 'f', 6, 64), strconv.Itoa(r.Length), strconv.Itoa(r.Optimal),
+		strconv.Itoa(r.Expanded), strconv.Itoa(r.Generated), strconv.Itoa(r.Frontier),
+		strconv.FormatFloat(float64(r.BestF), 'g', -1, 32), strconv.FormatFloat(float64(r.DeepestG), 'g', -1, 32),
+		strconv.Itoa(r.Stored), strconv.Itoa(r.Shared)}
+}
+
+/**
+ * Progress report of a running search, written to the progress file when progress.log is set
+ **/
+type ProgressRecord struct {
+	Label     string  `json:"label"`
+	Elapsed   float64 `json:"elapsed"` // seconds
+	Expanded  int     `json:"expanded"`
+	Generated int     `json:"generated"`
+	Rate      float64 `json:"rate"` // nodes expanded per second
+	Open      int     `json:"open"` // the current path for IDA*
+	Closed    int     `json:"closed"`
+	Bound     float32 `json:"f_bound"`
+	BestH     float32 `json:"best_h"`
+}
+
+var progress_csv_header = []string{"label", "elapsed", "expanded", "generated", "rate", "open", "closed", "f_bound", "best_h"}
+
+func (r ProgressRecord) csvRow() []string {
+	return []string{r.Label, strconv.FormatFloat(r.Elapsed, 'f', 3, 64), strconv.Itoa(r.Expanded),
+		strconv.Itoa(r.Generated), strconv.FormatFloat(r.Rate, 'f', 0, 64), strconv.Itoa(r.Open), strconv.Itoa(r.Closed),
+		strconv.FormatFloat(float64(r.Bound), 'g', -1, 32), strconv.FormatFloat(float64(r.BestH), 'g', -1, 32)}
+}
+
+func newProgressRecord(r *run, p search.Progress) ProgressRecord {
+	return ProgressRecord{
+		Label:     r.label,
+		Elapsed:   p.Elapsed.Seconds(),
+		Expanded:  p.Expanded,
+		Generated: p.Generated,
+		Rate:      p.Rate(),
+		Open:      p.Open,
+		Closed:    p.Closed,
+		Bound:     p.Bound,
+		BestH:     p.BestH,
+	}
+}
+
+/**
+ * Summary of the trials of an input, or the instances of a suite, for one heuristic. Written to the
+ * summary file as well as the text log, so it isn't lost without a text log. The stats are of solved runs
+ **/
+type SummaryRecord struct {
+	Input     int   `json:"input"`
+	Heuristic int   `json:"heuristic"`
+	Runs      int   `json:"runs"`
+	Solved    int   `json:"solved"`
+	Stopped   int   `json:"stopped"` // stopped early by a limit or an interrupt
+	Time      Stats `json:"time"`    // seconds
+	Expanded  Stats `json:"expanded"`
+	Length    Stats `json:"length"`
+}
+
+var summary_csv_header = append([]string{"input", "heuristic", "runs", "solved", "stopped"},
+	append(statsCSVHeader("time"), append(statsCSVHeader("expanded"), statsCSVHeader("length")...)...)...)
+
+func (r SummaryRecord) csvRow() []string {
+	var row []string = []string{strconv.Itoa(r.Input), strconv.Itoa(r.Heuristic), strconv.Itoa(r.Runs),
+		strconv.Itoa(r.Solved), strconv.Itoa(r.Stopped)}
+	return append(row, append(statsCSVRow(r.Time), append(statsCSVRow(r.Expanded), statsCSVRow(r.Length)...)...)...)
+}
+
+func statsCSVHeader(prefix string) []string {
+	var header []string
+	for _, name := range []string{"n", "mean", "median", "stddev", "min", "max", "p25", "p75", "p90"} {
+		header = append(header, prefix+"_"+name)
+	}
+	return header
+}
+
+func statsCSVRow(s Stats) []string {
+	var row []string = []string{strconv.Itoa(s.N)}
+	for _, v := range []float64{s.Mean, s.Median, s.Stddev, s.Min, s.Max, s.P25, s.P75, s.P90} {
+		row = append(row, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return row
+}
+
+/**
+ * Builds the record of a finished run
+ **/
+func newResultRecord(r *run) ResultRecord {
+	var length int = -1
+	if r.result.Status == search.Solved {
+		length = len(r.result.Path) - 1
+	}
+
+	return ResultRecord{
+		Label:     r.label,
+		Input:     r.input,
+		Instance:  r.instance.Number,
+		Seed:      r.seed,
+		Rows:      r.initial.Rows(),
+		Cols:      r.initial.Cols(),
+		Goal:      r.initial.Goal().LayoutStr(),
+		Initial:   boardStr(r.initial),
+		Heuristic: r.heuristic_num,
+		Algorithm: string(r.algorithm),
+		Status:    string(r.result.Status),
+		Time:      r.result.Duration.Seconds(),
+		Length:    length,
+		Optimal:   r.optimal,
+		Expanded:  r.result.Expanded,
+		Generated: r.result.Generated,
+		Frontier:  r.result.Frontier,
+		BestF:     r.result.BestF,
+		DeepestG:  r.result.DeepestG,
+		Stored:    r.result.Stored,
+		Shared:    r.shared,
+	}
+}
+
+/**
+ * A record that can be written as a CSV row as well as JSON
+ **/
+type structuredRecord interface {
+	csvRow() []string
+}
+
+/**
+ * File of structured records, safe to write from several goroutines
+ **/
+type resultsFile struct {
+	f    *os.File
+	csv  *csv.Writer
+	json *json.Encoder
+	lock sync.Mutex
+}
+
+func openResults(filename string, format string, header []string) *resultsFile {
+	var r = &resultsFile{f: openLogFile(filename)}
+	switch format {
+	case JSONLResults:
+		r.json = json.NewEncoder(r.f)
+	case CSVResults:
+		r.csv = csv.NewWriter(r.f)
+		r.csv.Write(header)
+	default:
+		panic(fmt.Sprintf("unknown results format %q", format))
+	}
+	return r
+}
+
+/**
+ * Writes a record straight away, so results so far are kept if a run is stopped
+ **/
+func (r *resultsFile) write(record structuredRecord) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	var err error
+	if r.json != nil {
+		err = r.json.Encode(record)
+	} else {
+		r.csv.Write(record.csvRow())
+		r.csv.Flush()
+		err = r.csv.Error()
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *resultsFile) close() {
+	r.f.Close()
+}
@@ -0,0 +1,70 @@
+package search
+
+import (
+	"container/heap"
+)
+
+/**
+ * Binary heap of nodes used as the open list of A*.
+ * Nodes are ordered by f = g + h, ties are broken in favour of the larger g
+ * as deeper nodes are usually closer to the goal.
+ * Each node tracks its own index in the heap so its priority can be decreased
+ * in place when a better path to it is found.
+ **/
+type PriorityQueue[S State[S]] []*Node[S]
+
+func (pq PriorityQueue[S]) Len() int {
+	return len(pq)
+}
+
+func (pq PriorityQueue[S]) Less(i, j int) bool {
+	fi, fj := pq[i].getF(), pq[j].getF()
+	if fi == fj {
+		return pq[i].g > pq[j].g
+	}
+	return fi < fj
+}
+
+func (pq PriorityQueue[S]) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+
+/**
+ * Push and Pop satisfy heap.Interface, use push and popLowest instead
+ **/
+func (pq *PriorityQueue[S]) Push(x any) {
+	n := x.(*Node[S])
+	n.index = len(*pq)
+	*pq = append(*pq, n)
+}
+
+func (pq *PriorityQueue[S]) Pop() any {
+	old := *pq
+	n := old[len(old)-1]
+	old[len(old)-1] = nil
+	n.index = -1
+	*pq = old[:len(old)-1]
+	return n
+}
+
+func (pq *PriorityQueue[S]) push(n *Node[S]) {
+	heap.Push(pq, n)
+}
+
+/**
+ * Removes and returns the node with the lowest f
+ **/
+func (pq *PriorityQueue[S]) popLowest() *Node[S] {
+	return heap.Pop(pq).(*Node[S])
+}
+
+/**
+ * Decrease-key: gives a node already in the queue a cheaper path and restores heap order
+ **/
+func (pq *PriorityQueue[S]) update(n *Node[S], g float32, prev *Node[S]) {
+	n.g = g
+	n.prev = prev
+	heap.Fix(pq, n.index)
+}
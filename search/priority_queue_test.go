@@ -0,0 +1,78 @@
+package search
+
+import "testing"
+
+func newTestNode(name string, g float32, h float32) *Node[graphState] {
+	return &Node[graphState]{state: graphState{name: name}, g: g, h: h}
+}
+
+func checkIndexes(t *testing.T, pq PriorityQueue[graphState]) {
+	t.Helper()
+	for i, n := range pq {
+		if n.index != i {
+			t.Fatalf("node %v has index %v at position %v", n.state.name, n.index, i)
+		}
+	}
+}
+
+func popNames(pq *PriorityQueue[graphState]) string {
+	var names string
+	for pq.Len() > 0 {
+		n := pq.popLowest()
+		if n.index != -1 {
+			return names + "(" + n.state.name + " still has an index)"
+		}
+		names += n.state.name
+	}
+	return names
+}
+
+func TestPriorityQueueOrder(t *testing.T) {
+	var cases = []struct {
+		name  string
+		nodes []*Node[graphState]
+		want  string
+	}{
+		{"lowest f first", []*Node[graphState]{newTestNode("c", 3, 3), newTestNode("a", 1, 0), newTestNode("b", 0, 2)}, "abc"},
+		// equal f, the larger g is deeper so usually closer to the goal
+		{"ties to larger g", []*Node[graphState]{newTestNode("c", 0, 5), newTestNode("a", 5, 0), newTestNode("b", 2, 3)}, "abc"},
+		{"f before g", []*Node[graphState]{newTestNode("b", 9, 1), newTestNode("a", 0, 4)}, "ab"},
+	}
+
+	for _, c := range cases {
+		var pq = PriorityQueue[graphState]{}
+		for _, n := range c.nodes {
+			pq.push(n)
+			checkIndexes(t, pq)
+		}
+		if got := popNames(&pq); got != c.want {
+			t.Errorf("%v: popped %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPriorityQueueUpdate(t *testing.T) {
+	var pq = PriorityQueue[graphState]{}
+	var nodes = map[string]*Node[graphState]{}
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		nodes[name] = newTestNode(name, float32(i+1), 10)
+		pq.push(nodes[name])
+	}
+
+	// decrease-key moves the node up in place and gives it its new parent
+	var parent = newTestNode("p", 0, 0)
+	pq.update(nodes["e"], 0, parent)
+	checkIndexes(t, pq)
+	if nodes["e"].prev != parent || nodes["e"].g != 0 {
+		t.Errorf("updated node has g %v and parent %v", nodes["e"].g, nodes["e"].prev)
+	}
+	pq.update(nodes["f"], 1.5, parent) // from the bottom of the heap to between a and b
+	checkIndexes(t, pq)
+	if pq.Len() != 6 {
+		t.Errorf("%v nodes after updates, want 6", pq.Len())
+	}
+
+	if got := popNames(&pq); got != "eafbcd" {
+		t.Errorf("popped %v, want eafbcd", got)
+	}
+}
@@ -0,0 +1,73 @@
+package search
+
+import (
+	"context"
+	"time"
+)
+
+/**
+ * Snapshot of a running search, reported periodically to the function given to WithProgress
+ **/
+type Progress struct {
+	Elapsed   time.Duration
+	Expanded  int     // nodes evaluated so far, over all iterations for IDA*
+	Generated int     // successors generated so far
+	Open      int     // open list size, the current path for IDA*
+	Closed    int     // closed list size, 0 for IDA*
+	Bound     float32 // f of the node being expanded, the iteration's bound for IDA*
+	BestH     float32 // lowest h expanded, how close the search has come to a goal
+}
+
+/**
+ * Nodes expanded per second so far
+ **/
+func (p Progress) Rate() float64 {
+	if p.Elapsed <= 0 {
+		return 0
+	}
+	return float64(p.Expanded) / p.Elapsed.Seconds()
+}
+
+type progressKey struct{}
+
+type progressReporter struct {
+	every  time.Duration
+	report func(Progress)
+}
+
+/**
+ * Context that makes searches run with it call report about every interval. Searches only look
+ * at the time every CheckInterval nodes, so report runs on the search's goroutine and should be quick
+ **/
+func WithProgress(ctx context.Context, every time.Duration, report func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, &progressReporter{every: every, report: report})
+}
+
+/**
+ * Decides when a search reports its progress. A search makes one from its context when it starts,
+ * and it does nothing if the context has no reporter
+ **/
+type ProgressTicker struct {
+	reporter *progressReporter
+	next     time.Time
+}
+
+func NewProgressTicker(ctx context.Context) *ProgressTicker {
+	reporter, _ := ctx.Value(progressKey{}).(*progressReporter)
+	if reporter == nil || reporter.every <= 0 || reporter.report == nil {
+		return &ProgressTicker{}
+	}
+	return &ProgressTicker{reporter: reporter, next: time.Now().Add(reporter.every)}
+}
+
+/**
+ * Returns if it's time to report, so the search only builds a Progress when it's needed
+ **/
+func (t *ProgressTicker) Due() bool {
+	return t.reporter != nil && !time.Now().Before(t.next)
+}
+
+func (t *ProgressTicker) Report(p Progress) {
+	t.next = time.Now().Add(t.reporter.every)
+	t.reporter.report(p)
+}
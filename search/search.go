@@ -0,0 +1,250 @@
+/**
+ * Package search is A* over any problem that implements State
+ **/
+package search
+
+import (
+	"context"
+	"errors"
+	"time"
+	"unsafe"
+)
+
+/**
+ * Search problems
+ * A* works on any state type that can identify itself, list its successors and recognise the goal.
+ * The heuristic is passed to the search rather than being part of the state, so one state type
+ * can be searched with several heuristics.
+ **/
+type State[S any] interface {
+	// hashable key, equal exactly when two states are equal
+	Key() StateKey
+	// states one move away and the cost of each move. skipReverse lets states that remember
+	// the move that reached them leave out the move that undoes it
+	Successors(skipReverse bool) []Successor[S]
+	IsGoal() bool
+}
+
+/**
+ * Hashable key identifying a state, so keys can be used for map lookups of the open and closed lists
+ **/
+type StateKey string
+
+type Successor[S any] struct {
+	State S
+	Cost  float32
+}
+
+type Status string
+
+const (
+	Solved      Status = "solved"
+	Timeout     Status = "timeout"      // the context's deadline passed
+	Cancelled   Status = "cancelled"    // the context was cancelled, like by an interrupt
+	NodeLimit   Status = "node limit"   // expanded the most nodes allowed by Limits
+	MemoryLimit Status = "memory limit" // stored the most nodes or bytes allowed by Limits
+	Unsolvable  Status = "unsolvable"
+)
+
+/**
+ * Budgets that stop a search early, 0 means no limit. Time limits are the context's deadline
+ **/
+type Limits struct {
+	Nodes  int   // nodes expanded
+	Stored int   // nodes kept in memory, the open and closed lists for A*
+	Bytes  int64 // approximate bytes of the nodes kept in memory, see NodeBytes
+}
+
+/**
+ * States that hold memory outside their own value, like the backing array of a slice, can say how
+ * much so the memory limit counts it
+ **/
+type Sizer interface {
+	HeapBytes() int
+}
+
+/**
+ * Approximate bytes A* keeps for a stored state with the given key: the node, its key, the state's
+ * own memory and the overhead of its map entry and heap slot. Go's real usage is higher, as maps
+ * and slices grow ahead of what they hold, so leave some room under the machine's memory
+ **/
+func NodeBytes[S State[S]](state S, key StateKey) int64 {
+	const entry_overhead = 48 // map bucket share, string header and heap slot pointer
+	var bytes int64 = int64(unsafe.Sizeof(Node[S]{})) + int64(len(key)) + entry_overhead
+	if sizer, ok := any(state).(Sizer); ok {
+		bytes += int64(sizer.HeapBytes())
+	}
+	return bytes
+}
+
+/**
+ * How often searches check their context, in nodes expanded. Checking every node costs more than the check is worth
+ **/
+const CheckInterval = 256
+
+/**
+ * Status of a search stopped because its context is done, Timeout past the deadline and Cancelled otherwise
+ **/
+func ContextStatus(ctx context.Context) Status {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return Timeout
+	}
+	return Cancelled
+}
+
+/**
+ * Outcome of a search. Path is stored goal first and is empty unless the status is Solved.
+ * BestF, DeepestG and Stored say how far a search got when it stops early
+ **/
+type Result[S any] struct {
+	Status     Status
+	Path       []S
+	Duration   time.Duration
+	Expanded   int       // nodes evaluated, the closed list for A*
+	Generated  int       // successors generated
+	Frontier   int       // open list size when the search stopped, 0 for IDA*
+	Thresholds []float32 // f bound of each iteration of IDA*
+	BestF      float32   // highest f expanded, a lower bound on the solution cost with a consistent heuristic
+	DeepestG   float32   // highest g expanded
+	Stored     int       // nodes kept in memory when the search stopped, the longest path for IDA*
+	Bytes      int64     // approximate bytes of the stored nodes, 0 for IDA*
+}
+
+type Node[S State[S]] struct {
+	state S
+	g     float32  // cost
+	h     float32  // heuristic
+	prev  *Node[S] // the predecessor
+	index int      // position in the open list heap
+}
+
+func (n Node[S]) getF() float32 {
+	return n.g + n.h
+}
+
+func (n Node[S]) isFinal() bool {
+	return n.state.IsGoal()
+}
+
+/**
+ * A* from initial to the nearest goal, h must be admissible for the path to be optimal.
+ * A closed node is reopened when a cheaper path to it is found, so heuristics that are admissible
+ * but not consistent, like the best over every blank position of a pattern database, still give
+ * optimal paths. With a consistent heuristic that never happens.
+ * The search stops when ctx is done or a limit is reached, returning the metrics so far.
+ * Progress is reported if ctx was made by WithProgress
+ **/
+func AStar[S State[S]](ctx context.Context, initial S, h func(S) float32, limits Limits, skipReverse bool) Result[S] {
+	start := time.Now()
+	var root = &Node[S]{
+		state: initial,
+		g:     0,
+		h:     h(initial),
+	}
+	var openList = PriorityQueue[S]{}
+	openList.push(root) // frontier starts with the initial state
+	var openIndex = map[StateKey]*Node[S]{initial.Key(): root}
+
+	var closedList = map[StateKey]*Node[S]{} // explored is empty
+	var generated int = 0
+	var bestF, deepestG float32 = 0, 0
+	var bestH float32 = root.h
+	var progress = NewProgressTicker(ctx)
+	var bytes int64 = NodeBytes(initial, initial.Key()) // every node stays stored, in the open list then the closed list
+	var result = func(status Status, path []S) Result[S] {
+		return Result[S]{
+			Status:    status,
+			Path:      path,
+			Duration:  time.Since(start),
+			Expanded:  len(closedList),
+			Generated: generated,
+			Frontier:  len(openList),
+			BestF:     bestF,
+			DeepestG:  deepestG,
+			Stored:    len(openList) + len(closedList),
+			Bytes:     bytes,
+		}
+	}
+
+	var cur *Node[S]
+	for len(openList) > 0 { // while there are nodes to explore
+		// stopping conditions
+		if len(closedList)%CheckInterval == 0 {
+			if ctx.Err() != nil {
+				return result(ContextStatus(ctx), make([]S, 0))
+			}
+			if progress.Due() {
+				progress.Report(Progress{
+					Elapsed:   time.Since(start),
+					Expanded:  len(closedList),
+					Generated: generated,
+					Open:      len(openList),
+					Closed:    len(closedList),
+					Bound:     openList[0].getF(),
+					BestH:     bestH,
+				})
+			}
+		}
+		if limits.Nodes > 0 && len(closedList) >= limits.Nodes {
+			return result(NodeLimit, make([]S, 0))
+		}
+		if (limits.Stored > 0 && len(openList)+len(closedList) >= limits.Stored) || (limits.Bytes > 0 && bytes >= limits.Bytes) {
+			return result(MemoryLimit, make([]S, 0))
+		}
+
+		cur = openList.popLowest()
+		curKey := cur.state.Key()
+		delete(openIndex, curKey)
+		if cur.getF() > bestF {
+			bestF = cur.getF()
+		}
+		if cur.g > deepestG {
+			deepestG = cur.g
+		}
+		if cur.h < bestH {
+			bestH = cur.h
+		}
+
+		if cur.isFinal() { // found solution
+			var path []S = make([]S, 0)
+			for node := cur; node != nil; node = node.prev {
+				path = append(path, node.state)
+			}
+
+			return result(Solved, path)
+
+		} else { // still exploring
+			closedList[curKey] = cur
+			for _, succ := range cur.state.Successors(skipReverse) {
+				generated++
+				key := succ.State.Key()
+				g := cur.g + succ.Cost
+
+				if node, ok := openIndex[key]; ok { // state is in open list
+					if g < node.g { // update with better path
+						node.state = succ.State // remembers the move from its new parent
+						openList.update(node, g, cur)
+					}
+				} else if node, ok := closedList[key]; ok { // state is in closed list
+					if g < node.g { // reopen with better path, only with an inconsistent heuristic
+						delete(closedList, key)
+						node.state, node.g, node.prev = succ.State, g, cur
+						openList.push(node)
+						openIndex[key] = node
+					}
+				} else { // state has not been seen yet
+					node := &Node[S]{
+						state: succ.State,
+						g:     g,
+						h:     h(succ.State),
+						prev:  cur,
+					}
+					openList.push(node)
+					openIndex[key] = node
+					bytes += NodeBytes(succ.State, key)
+				}
+			}
+		}
+	}
+	return result(Unsolvable, make([]S, 0))
+}
@@ -0,0 +1,105 @@
+package search
+
+import (
+	"context"
+	"testing"
+)
+
+/**
+ * Small weighted graph for testing searches, edges[from][to] is the cost of a move
+ **/
+type graph struct {
+	edges map[string]map[string]float32
+	goal  string
+}
+
+type graphState struct {
+	graph *graph
+	name  string
+}
+
+func (s graphState) Key() StateKey {
+	return StateKey(s.name)
+}
+
+func (s graphState) Successors(skipReverse bool) []Successor[graphState] {
+	var succs []Successor[graphState]
+	for _, to := range sortedKeys(s.graph.edges[s.name]) {
+		succs = append(succs, Successor[graphState]{State: graphState{s.graph, to}, Cost: s.graph.edges[s.name][to]})
+	}
+	return succs
+}
+
+func (s graphState) IsGoal() bool {
+	return s.name == s.graph.goal
+}
+
+func sortedKeys(m map[string]float32) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	for i := 1; i < len(keys); i++ {
+		for j := i; j > 0 && keys[j] < keys[j-1]; j-- {
+			keys[j], keys[j-1] = keys[j-1], keys[j]
+		}
+	}
+	return keys
+}
+
+func pathNames(path []graphState) string {
+	var names string
+	for i := len(path) - 1; i >= 0; i-- { // paths are stored goal first
+		names += path[i].name
+	}
+	return names
+}
+
+func TestAStarReopensClosedNodes(t *testing.T) {
+	// h(A) = 4 is admissible but not consistent, so C is first closed through B with g = 4
+	// and has to be reopened when A finds it with g = 2
+	var g = &graph{goal: "G", edges: map[string]map[string]float32{
+		"S": {"A": 1, "B": 1},
+		"A": {"C": 1},
+		"B": {"C": 3},
+		"C": {"G": 3},
+	}}
+	var h = func(s graphState) float32 {
+		if s.name == "A" {
+			return 4
+		}
+		return 0
+	}
+
+	result := AStar(context.Background(), graphState{g, "S"}, h, Limits{}, false)
+	if result.Status != Solved {
+		t.Fatalf("status %v", result.Status)
+	}
+	if names := pathNames(result.Path); names != "SACG" {
+		t.Errorf("path %v, want SACG", names)
+	}
+	if result.Expanded != 4 {
+		t.Errorf("expanded %v, want 4 as C is closed again under the same key", result.Expanded)
+	}
+}
+
+func TestAStarStops(t *testing.T) {
+	var g = &graph{goal: "G", edges: map[string]map[string]float32{
+		"S": {"A": 1, "B": 2},
+		"A": {"S": 1},
+	}}
+	var zero = func(graphState) float32 { return 0 }
+
+	if result := AStar(context.Background(), graphState{g, "S"}, zero, Limits{}, false); result.Status != Unsolvable || len(result.Path) != 0 {
+		t.Errorf("unreachable goal: status %v, path %v", result.Status, pathNames(result.Path))
+	}
+	if result := AStar(context.Background(), graphState{g, "S"}, zero, Limits{Nodes: 1}, false); result.Status != NodeLimit || result.Expanded != 1 {
+		t.Errorf("node limit: status %v after %v nodes", result.Status, result.Expanded)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if result := AStar(ctx, graphState{g, "S"}, zero, Limits{}, false); result.Status != Cancelled {
+		t.Errorf("cancelled: status %v", result.Status)
+	}
+}
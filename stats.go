@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"tile-puzzle-ai/puzzle"
+	"tile-puzzle-ai/search"
+)
+
+/**
+ * Summary statistics of the trials of an input, or the instances of a suite, for each heuristic
+ **/
+
+type Stats struct {
+	N      int     `json:"n"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	Stddev float64 `json:"stddev"` // sample standard deviation
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	P25    float64 `json:"p25"`
+	P75    float64 `json:"p75"`
+	P90    float64 `json:"p90"`
+}
+
+/**
+ * Summarises values, which are sorted in place
+ **/
+func summarize(values []float64) Stats {
+	var s = Stats{N: len(values)}
+	if len(values) == 0 {
+		return s
+	}
+
+	sort.Float64s(values)
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	s.Mean = sum / float64(len(values))
+
+	if len(values) > 1 {
+		var squares float64
+		for _, v := range values {
+			squares += (v - s.Mean) * (v - s.Mean)
+		}
+		s.Stddev = math.Sqrt(squares / float64(len(values)-1))
+	}
+
+	s.Min, s.Max = values[0], values[len(values)-1]
+	s.Median = percentile(values, 50)
+	s.P25 = percentile(values, 25)
+	s.P75 = percentile(values, 75)
+	s.P90 = percentile(values, 90)
+	return s
+}
+
+/**
+ * Percentile p of sorted values, interpolating between the closest ranks
+ **/
+func percentile(sorted []float64, p float64) float64 {
+	var rank float64 = p / 100 * float64(len(sorted)-1)
+	var lower int = int(math.Floor(rank))
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	var frac float64 = rank - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
+
+/**
+ * Outcomes of every run of one heuristic. Times, expanded nodes and lengths are only kept for
+ * solved runs, as runs stopped by the time limit would pull them down
+ **/
+type heuristicTrials struct {
+	heuristic_num int
+	runs          int
+	solved        int
+	stopped       int // stopped early by a limit or an interrupt
+	times         []float64
+	expanded      []float64
+	lengths       []float64
+}
+
+type trialStats []*heuristicTrials
+
+func (stats trialStats) runs() int {
+	var runs int = 0
+	for _, h := range stats {
+		runs += h.runs
+	}
+	return runs
+}
+
+func newTrialStats(heuristics []int) trialStats {
+	var stats trialStats
+	for _, heuristic_num := range heuristics {
+		stats = append(stats, &heuristicTrials{heuristic_num: heuristic_num})
+	}
+	return stats
+}
+
+func (stats trialStats) add(heuristic_num int, result puzzle.Result) {
+	for _, h := range stats {
+		if h.heuristic_num != heuristic_num {
+			continue
+		}
+		h.runs++
+		switch result.Status {
+		case search.Solved:
+			h.solved++
+			h.times = append(h.times, result.Duration.Seconds())
+			h.expanded = append(h.expanded, float64(result.Expanded))
+			h.lengths = append(h.lengths, float64(len(result.Path)-1))
+		case search.Timeout, search.NodeLimit, search.MemoryLimit, search.Cancelled:
+			h.stopped++
+		}
+		return
+	}
+}
+
+/**
+ * Summary of an input's runs for each heuristic that ran
+ **/
+func trialSummaries(input int, stats trialStats) []SummaryRecord {
+	var summaries []SummaryRecord
+	for _, h := range stats {
+		if h.runs == 0 {
+			continue
+		}
+		summaries = append(summaries, SummaryRecord{
+			Input:     input,
+			Heuristic: h.heuristic_num,
+			Runs:      h.runs,
+			Solved:    h.solved,
+			Stopped:   h.stopped,
+			Time:      summarize(h.times),
+			Expanded:  summarize(h.expanded),
+			Length:    summarize(h.lengths),
+		})
+	}
+	return summaries
+}
+
+/**
+ * Logs the summary of an input's runs for each heuristic
+ **/
+func logTrialSummary(summaries []SummaryRecord) {
+	for _, s := range summaries {
+		logger.Printf("Summary: %v-%v, %v runs\n", s.Input, s.Heuristic, s.Runs)
+		logger.Printf("Solved: %v / %v (%.1f%%), Stopped Early: %v\n", s.Solved, s.Runs, 100*float64(s.Solved)/float64(s.Runs), s.Stopped)
+		if s.Solved > 0 {
+			logger.Printf("Execution time (solved): %v\n", statsStr(s.Time, "%.3fs"))
+			logger.Printf("Nodes Expanded (solved): %v\n", statsStr(s.Expanded, "%.0f"))
+			logger.Printf("Solution Length (solved): %v\n", statsStr(s.Length, "%.1f"))
+		}
+		logger.Print(logFileSpacer())
+	}
+}
+
+func statsStr(s Stats, format string) string {
+	var f = func(v float64) string { return fmt.Sprintf(format, v) }
+	return fmt.Sprintf("mean %v, median %v, stddev %v, min %v, max %v, p25 %v, p75 %v, p90 %v",
+		f(s.Mean), f(s.Median), f(s.Stddev), f(s.Min), f(s.Max), f(s.P25), f(s.P75), f(s.P90))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"tile-puzzle-ai/puzzle"
+	"tile-puzzle-ai/search"
+	"time"
+)
+
+/**
+ * Live status line on stderr showing the latest progress report of the searches running.
+ * Each report overwrites the last, so it's only used when stderr is a terminal
+ **/
+type statusLine struct {
+	out   *os.File
+	width int // length of the text shown, to blank it out
+	lock  sync.Mutex
+}
+
+var status *statusLine // nil unless the config asks for a status line and stderr is a terminal
+
+func (s *statusLine) show(text string) {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var pad int = s.width - len(text)
+	if pad < 0 {
+		pad = 0
+	}
+	fmt.Fprint(s.out, "\r"+text+strings.Repeat(" ", pad))
+	s.width = len(text)
+}
+
+/**
+ * Blanks the line so other output starts on a clean line
+ **/
+func (s *statusLine) clear() {
+	if s == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.width > 0 {
+		fmt.Fprint(s.out, "\r"+strings.Repeat(" ", s.width)+"\r")
+		s.width = 0
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+/**
+ * Handles a progress report of a run, showing it on the status line and logging it
+ **/
+func reportProgress(r *run, p search.Progress) {
+	status.show(progressStr(r, p))
+	if progress_file != nil {
+		progress_file.write(newProgressRecord(r, p))
+	}
+}
+
+func progressStr(r *run, p search.Progress) string {
+	var lists string
+	if r.algorithm == puzzle.IDAStar {
+		lists = fmt.Sprintf("path %v", p.Open)
+	} else {
+		lists = fmt.Sprintf("open %v, closed %v", p.Open, p.Closed)
+	}
+	return fmt.Sprintf("Puzzle %v: %v, %v expanded (%.0f/s), %v, f %v, best h %v",
+		r.label, p.Elapsed.Round(time.Second/10), p.Expanded, p.Rate(), lists, p.Bound, p.BestH)
+}
@@ -0,0 +1,385 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"tile-puzzle-ai/puzzle"
+)
+
+/**
+ * A problem with a config, at a JSON path like inputs[3].heuristics[1]
+ **/
+type ConfigError struct {
+	Path string
+	Msg  string
+}
+
+func (e ConfigError) Error() string {
+	if e.Path == "" {
+		return e.Msg
+	}
+	return e.Path + ": " + e.Msg
+}
+
+/**
+ * Decodes a config, returning every value that has the wrong type instead of only the first.
+ * Values with the wrong type are left as zero and the rest are decoded, so they can still be checked.
+ * decoded is false if the JSON itself is invalid and nothing could be decoded
+ **/
+func decodeConfig(data []byte) (config Config, errs []ConfigError, decoded bool) {
+	err := json.Unmarshal(data, &config)
+	if err == nil {
+		return config, nil, true
+	}
+
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		line, col := lineCol(data, syntax.Offset)
+		return Config{}, []ConfigError{{Path: fmt.Sprintf("line %v column %v", line, col), Msg: syntax.Error()}}, false
+	}
+	return config, decodeInto(data, "", reflect.ValueOf(&config).Elem()), true
+}
+
+var unmarshaler_type = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+/**
+ * Decodes data into v, decoding each field and element on its own when the whole doesn't decode so only
+ * the values with the wrong type are left as zero. Returns the values that didn't decode
+ **/
+func decodeInto(data json.RawMessage, path string, v reflect.Value) []ConfigError {
+	err := json.Unmarshal(data, v.Addr().Interface())
+	if err == nil {
+		return nil
+	}
+
+	if reflect.PointerTo(v.Type()).Implements(unmarshaler_type) { // parsed as a whole, like initial and goal
+		return []ConfigError{{Path: path, Msg: decodeErrorMsg(err)}}
+	}
+
+	v.Set(reflect.Zero(v.Type())) // drop whatever was decoded before the error
+	switch v.Kind() {
+	case reflect.Struct:
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(data, &fields) != nil {
+			return []ConfigError{{Path: path, Msg: decodeErrorMsg(err)}}
+		}
+
+		var keys []string
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var errs []ConfigError
+		for _, key := range keys {
+			if field, ok := jsonField(v.Type(), key); ok { // extra values are ignored
+				errs = append(errs, decodeInto(fields[key], joinPath(path, key), v.FieldByIndex(field.Index))...)
+			}
+		}
+		return errs
+
+	case reflect.Slice:
+		var elems []json.RawMessage
+		if json.Unmarshal(data, &elems) != nil {
+			return []ConfigError{{Path: path, Msg: decodeErrorMsg(err)}}
+		}
+
+		v.Set(reflect.MakeSlice(v.Type(), len(elems), len(elems)))
+		var errs []ConfigError
+		for i, elem := range elems {
+			errs = append(errs, decodeInto(elem, fmt.Sprintf("%v[%v]", path, i), v.Index(i))...)
+		}
+		return errs
+	}
+
+	return []ConfigError{{Path: path, Msg: decodeErrorMsg(err)}}
+}
+
+/**
+ * Adds the problems validateConfig finds to the values of config that decoded, leaving out those
+ * at or inside a value that didn't decode as they come from it being left as zero
+ **/
+func checkDecoded(config *Config, decode_errs []ConfigError) []ConfigError {
+	var undecoded = map[string]bool{}
+	for _, err := range decode_errs {
+		undecoded[err.Path] = true
+	}
+
+	var errs []ConfigError = decode_errs
+	for _, err := range validateConfig(config, undecoded) {
+		var from_decode bool = false
+		for _, decode_err := range decode_errs {
+			from_decode = from_decode || isWithin(err.Path, decode_err.Path)
+		}
+		if !from_decode {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+/**
+ * Whether path is parent or a value inside it, like inputs[2].heuristics[0] is inside inputs[2]
+ **/
+func isWithin(path string, parent string) bool {
+	return path == parent || strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[")
+}
+
+/**
+ * The struct field a JSON key decodes into, matched case insensitively like encoding/json
+ **/
+func jsonField(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		var name string = strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if strings.EqualFold(name, key) {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func decodeErrorMsg(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return err.Error()
+	}
+
+	var expected string
+	switch typeErr.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		expected = "an integer"
+	case reflect.Float32, reflect.Float64:
+		expected = "a number"
+	case reflect.Bool:
+		expected = "true or false"
+	case reflect.String:
+		expected = "a string"
+	case reflect.Slice, reflect.Array:
+		expected = "an array"
+	default:
+		expected = "an object"
+	}
+	return fmt.Sprintf("expected %v, got %v", expected, typeErr.Value)
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+/**
+ * Line and column, both from 1, of a byte offset in data
+ **/
+func lineCol(data []byte, offset int64) (int, int) {
+	var line, col int = 1, 1
+	for _, c := range data[:offset] {
+		if c == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return line, col
+}
+
+/**
+ * Checks the values of a decoded config, returning every problem found. undecoded holds the paths of
+ * values that had the wrong type and were left as zero, so the checks that depend on them are skipped.
+ * Inputs without a goal are given the default goal, so every input knows its goal
+ **/
+func validateConfig(config *Config, undecoded map[string]bool) []ConfigError {
+	var errs []ConfigError
+	var add = func(path string, format string, args ...any) {
+		errs = append(errs, ConfigError{Path: path, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if config.Workers < 0 {
+		add("workers", "must not be negative")
+	}
+	if !validResultsFormat(config.Results.Format) {
+		add("results.format", "unknown format %q, use %q, %q or \"none\"", config.Results.Format, JSONLResults, CSVResults)
+	}
+	if config.Results.No_text_log && !writesResults(*config) {
+		add("results.no text log", "nothing would be written, set results.format")
+	}
+	if config.Progress.Interval < 0 {
+		add("progress.interval", "must not be negative")
+	}
+	if config.Progress.Log && !writesResults(*config) {
+		add("progress.log", "progress is written in the results format, set results.format")
+	}
+
+	var defaults = &config.Default_inputs
+	if !validAlgorithm(defaults.Algorithm) {
+		add("default inputs.algorithm", "unknown algorithm %q, use \"a*\" or \"ida*\"", defaults.Algorithm)
+	}
+	if defaults.Time_limit < 0 {
+		add("default inputs.time limit", "must not be negative")
+	}
+	if defaults.Node_limit < 0 {
+		add("default inputs.node limit", "must not be negative")
+	}
+	if defaults.Stored_limit < 0 {
+		add("default inputs.stored limit", "must not be negative")
+	}
+	if defaults.Memory_limit < 0 {
+		add("default inputs.memory limit", "must not be negative")
+	}
+	checkHeuristics(defaults.Heuristics, "default inputs.heuristics", add)
+	var bad_pdbs = map[string]bool{} // pattern databases that couldn't be read, reported once
+
+	for i, input := range config.Inputs {
+		var path string = fmt.Sprintf("inputs[%v]", i)
+		if !input.Goal.IsSet() {
+			input.Goal = defaults.Goal
+			config.Inputs[i].Goal = input.Goal
+		}
+
+		if input.Time_limit < 0 {
+			add(path+".time limit", "must not be negative")
+		}
+		if input.Memory_limit < 0 {
+			add(path+".memory limit", "must not be negative")
+		}
+
+		var negative bool = false
+		for _, field := range []struct {
+			name  string
+			value int
+		}{{"size", input.Size}, {"rows", input.Rows}, {"cols", input.Cols}, {"first", input.First}, {"last", input.Last},
+			{"swaps", input.Swaps}, {"depth", input.Depth}, {"misplaced", input.Misplaced}, {"node limit", input.Node_limit},
+			{"stored limit", input.Stored_limit}, {"trials", input.Trials}} {
+			if field.value < 0 {
+				add(path+"."+field.name, "must not be negative")
+				negative = true
+			}
+		}
+
+		if input.Misplaced != 0 && input.Swaps != 0 {
+			add(path, "cannot specify both swaps and misplaced")
+		}
+		if input.Uniform && (input.Initial.tiles != nil || input.Suite != "" || input.Swaps != 0 || input.Misplaced != 0) {
+			add(path+".uniform", "cannot specify uniform with initial, suite, swaps or misplaced")
+		}
+		if input.Depth != 0 && (input.Uniform || input.Initial.tiles != nil || input.Suite != "" || input.Swaps != 0 || input.Misplaced != 0) {
+			add(path+".depth", "cannot specify depth with uniform, initial, suite, swaps or misplaced")
+		}
+
+		if input.Trials > 1 && !input.Uniform && input.Depth == 0 && input.Swaps == 0 && input.Misplaced == 0 {
+			add(path+".trials", "trials need a randomly generated puzzle, set uniform, depth, swaps or misplaced")
+		}
+
+		var bad_range bool = false
+		if input.Suite != "" {
+			if input.Initial.tiles != nil || input.Swaps != 0 || input.Misplaced != 0 {
+				add(path+".suite", "cannot specify suite with initial, swaps or misplaced")
+			}
+			if input.Last != 0 && input.First > input.Last {
+				add(path+".first", "first is after last")
+				bad_range = true
+			}
+			if input.Suite == "random" && input.Last == 0 {
+				add(path+".last", "the random suite has no end, set last")
+			}
+		}
+
+		// the shape is only checked when the sizes are valid, and suites other than random have their own
+		var rows, cols int = input.shape()
+		var has_shape bool = !negative && (input.Suite == "" || input.Suite == "random")
+		if input.Initial.tiles != nil {
+			if err := checkInitial(input); err != nil {
+				add(path+".initial", "%v", err)
+				has_shape = false
+			}
+		} else if undecoded[path+".initial"] || undecoded[path+".size"] || undecoded[path+".rows"] || undecoded[path+".cols"] {
+			has_shape = false // the shape is unknown, and the value was reported when decoding
+		} else if has_shape && (rows < 2 || cols < 2) {
+			add(path+".size", "puzzles need at least 2 rows and columns, set size or rows and cols")
+			has_shape = false
+		} else if has_shape && rows*cols > 256 {
+			add(path+".size", "puzzle has %v tiles, at most 256 are supported", rows*cols)
+			has_shape = false
+		}
+		var goals []*puzzle.Goal // the goals the input's puzzles are solved towards, for checking its pattern database
+		if has_shape {
+			if goal, err := input.Goal.Goal(rows, cols); err != nil {
+				add(path+".goal", "%v", err)
+			} else {
+				goals = []*puzzle.Goal{goal}
+			}
+		} else if input.Suite != "" && input.Suite != "random" && !negative && !bad_range {
+			var err error
+			if goals, err = puzzle.SuiteGoals(input.Suite, input.Goal, rows, cols, input.First, input.Last); err != nil {
+				add(path+".suite", "%v", err)
+			}
+		}
+
+		if !validAlgorithm(input.Algorithm) {
+			add(path+".algorithm", "unknown algorithm %q, use \"a*\" or \"ida*\"", input.Algorithm)
+		}
+
+		var heuristics []int = input.Heuristics
+		var heuristics_path string = path + ".heuristics"
+		if heuristics == nil {
+			heuristics, heuristics_path = defaults.Heuristics, "default inputs.heuristics"
+		} else {
+			checkHeuristics(heuristics, heuristics_path, add)
+		}
+		for j, heuristic_num := range heuristics {
+			if heuristic_num != puzzle.PDBHeuristic {
+				continue
+			}
+			if input.Pdb == "" && defaults.Pdb == "" {
+				add(fmt.Sprintf("%v[%v]", heuristics_path, j), "pattern database heuristic needs a pdb in %v or default inputs", path)
+			} else if input.Pdb != "" {
+				checkPatternDB(input.Pdb, path+".pdb", path, goals, bad_pdbs, add)
+			} else {
+				checkPatternDB(defaults.Pdb, "default inputs.pdb", path, goals, bad_pdbs, add)
+			}
+			break
+		}
+	}
+
+	return errs
+}
+
+/**
+ * Checks the pattern database name can be read and was built for every goal the input at input_path uses.
+ * A file that can't be read is only reported once, bad_pdbs holds the ones already reported
+ **/
+func checkPatternDB(name string, path string, input_path string, goals []*puzzle.Goal, bad_pdbs map[string]bool, add func(path string, format string, args ...any)) {
+	if bad_pdbs[name] {
+		return
+	}
+	pdb, err := loadPatternDB(name)
+	if err != nil {
+		bad_pdbs[name] = true
+		if len(goals) > 0 {
+			add(path, "couldn't load pattern database: %v, generate one with: %v", err, pdbCommand(name, puzzle.NewPuzzleSolved(goals[0])))
+		} else {
+			add(path, "couldn't load pattern database: %v", err)
+		}
+		return
+	}
+
+	for _, goal := range goals {
+		if err := pdb.CheckCompatible(puzzle.NewPuzzleSolved(goal)); err != nil {
+			add(path, "can't use %v for %v: %v", name, input_path, err)
+			return
+		}
+	}
+}
+
+func checkHeuristics(heuristics []int, path string, add func(path string, format string, args ...any)) {
+	for j, heuristic_num := range heuristics {
+		if heuristic_num < 1 || heuristic_num > puzzle.NumHeuristics {
+			add(fmt.Sprintf("%v[%v]", path, j), "unknown heuristic %v, heuristics are 1 to %v", heuristic_num, puzzle.NumHeuristics)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func errorPaths(errs []ConfigError) []string {
+	var paths []string = []string{}
+	for _, err := range errs {
+		paths = append(paths, err.Path)
+	}
+	return paths
+}
+
+func TestDecodeInto(t *testing.T) {
+	var cases = []struct {
+		name  string
+		json  string
+		paths []string
+	}{
+		{"valid", `{"workers": 2, "inputs": [{"size": 3}]}`, []string{}},
+		{"type error", `{"workers": "two"}`, []string{"workers"}},
+		{"nested object", `{"results": {"no text log": 1}, "progress": {"interval": "1s"}}`,
+			[]string{"progress.interval", "results.no text log"}},
+		{"array element", `{"inputs": [{"size": 3}, {"heuristics": [1, "2", 3, true]}]}`,
+			[]string{"inputs[1].heuristics[1]", "inputs[1].heuristics[3]"}},
+		{"whole array", `{"inputs": {"size": 3}}`, []string{"inputs"}},
+		{"unmarshaler", `{"inputs": [{"initial": 5, "goal": 3}]}`, []string{"inputs[0].goal", "inputs[0].initial"}},
+	}
+
+	for _, c := range cases {
+		config, errs, decoded := decodeConfig([]byte(c.json))
+		if !decoded {
+			t.Fatalf("%v: not decoded", c.name)
+		}
+		if paths := errorPaths(errs); !reflect.DeepEqual(paths, c.paths) {
+			t.Errorf("%v: errors at %v, want %v", c.name, paths, c.paths)
+		}
+		if c.name == "array element" {
+			if got := config.Inputs[1].Heuristics; !reflect.DeepEqual(got, []int{1, 0, 3, 0}) {
+				t.Errorf("%v: heuristics %v, want the bad ones left as zero", c.name, got)
+			}
+			if config.Inputs[0].Size != 3 {
+				t.Errorf("%v: size %v of the other input wasn't decoded", c.name, config.Inputs[0].Size)
+			}
+		}
+	}
+
+	if _, errs, decoded := decodeConfig([]byte(`{"workers": 2,}`)); decoded || len(errs) != 1 || !strings.HasPrefix(errs[0].Path, "line 1 column") {
+		t.Errorf("invalid JSON: decoded %v, errors %v", decoded, errs)
+	}
+}
+
+func TestCheckDecoded(t *testing.T) {
+	var cases = []struct {
+		name  string
+		json  string
+		paths []string
+	}{
+		{"undecoded rows", `{"inputs": [{"size": 3}, {"rows": "3", "cols": 3}]}`, []string{"inputs[1].rows"}},
+		{"undecoded size", `{"inputs": [{"size": [3]}]}`, []string{"inputs[0].size"}},
+		{"undecoded initial", `{"inputs": [{"initial": "1 2 / 3"}]}`, []string{"inputs[0].initial"}},
+		{"missing shape", `{"inputs": [{"uniform": true}]}`, []string{"inputs[0].size"}},
+		{"decode and validation errors", `{"workers": -1, "inputs": [{"size": 3, "goal": "weird", "heuristics": [1, "x", 99]}]}`,
+			[]string{"inputs[0].goal", "inputs[0].heuristics[1]", "workers", "inputs[0].heuristics[2]"}},
+		{"first after last", `{"inputs": [{"suite": "korf100", "first": 5, "last": 2}]}`, []string{"inputs[0].first"}},
+		{"first past the end", `{"inputs": [{"suite": "korf100", "first": 101}]}`, []string{"inputs[0].suite"}},
+	}
+
+	for _, c := range cases {
+		config, errs, decoded := decodeConfig([]byte(c.json))
+		if !decoded {
+			t.Fatalf("%v: not decoded", c.name)
+		}
+		if paths := errorPaths(checkDecoded(&config, errs)); !reflect.DeepEqual(paths, c.paths) {
+			t.Errorf("%v: errors at %v, want %v", c.name, paths, c.paths)
+		}
+	}
+}